@@ -2,50 +2,536 @@ package doc
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"runtime/debug"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
+// cacheConfig holds the options of the cached searcher, set with the cache
+// related SearchOptions.
+type cacheConfig struct {
+	store       Store
+	maxEntries  int
+	maxAge      time.Duration
+	notFoundTTL time.Duration
+
+	janitorCtx      context.Context
+	janitorInterval time.Duration
+
+	staleWhileRevalidate bool
+	onRefresh            func(module string, stale, fresh Package)
+}
+
 type cachedSearcher struct {
 	Searcher
+	config cacheConfig
+
+	mu       sync.RWMutex
+	store    Store
+	notFound map[string]notFoundEntry
+	group    singleflight.Group
+	// aliases maps the resolved version of unversioned searches, such as
+	// path@v1.2.3, to the key the package is stored under, such as path.
+	// Aliases are not stored, and do not count as entries.
+	aliases map[string]string
+	flights map[string]*flight
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	inFlight  atomic.Int64
+
+	// ctx is cancelled when the searcher is closed, and is used for background
+	// refreshes.
+	ctx        context.Context
+	cancel     context.CancelFunc
+	refreshing map[string]bool
+	refreshes  sync.WaitGroup
+	// closed is set under mu by Close, after which no refreshes are started.
+	closed bool
 
-	mu    sync.RWMutex
-	cache map[string]*CachedPackage
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
 }
 
 type CachedPackage struct {
 	Package
 	Created time.Time
 	Updated time.Time
+
+	// ETag and LastModified are the validators of the response the package
+	// was parsed from, used to revalidate the package once it has expired.
+	ETag         string
+	LastModified string
+}
+
+// notFoundEntry is a cached not found error.
+type notFoundEntry struct {
+	err     error
+	expires time.Time
 }
 
+func newCachedSearcher(s Searcher, config cacheConfig) *cachedSearcher {
+	if config.store == nil {
+		config.store = NewMemoryStore()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &cachedSearcher{
+		Searcher:   s,
+		config:     config,
+		store:      config.store,
+		notFound:   map[string]notFoundEntry{},
+		aliases:    map[string]string{},
+		flights:    map[string]*flight{},
+		ctx:        ctx,
+		cancel:     cancel,
+		refreshing: map[string]bool{},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	if config.janitorCtx == nil {
+		close(c.done)
+		return c
+	}
+	if config.janitorInterval <= 0 {
+		config.janitorInterval = time.Minute
+	}
+	go c.janitor(config.janitorCtx, config.janitorInterval)
+	return c
+}
+
+// Search returns the cached package for the module if there is one, and
+// searches for it otherwise.
+//
+// Results are cached under the requested module, with "@latest" removed. The
+// path and version the result resolved to is kept as an alias of that entry, so
+// that searching for the latest version and the same exact version share an
+// entry. Aliases are only kept in memory, and are not counted as entries.
+//
+// Entries created longer than the maximum age ago are not returned. If the
+// expired entry was fetched over http, it is revalidated with a conditional
+// request, and reused if the page has not been modified. Otherwise it is
+// replaced by the new result.
+//
+// With WithStaleWhileRevalidate, expired entries are returned immediately
+// instead, and revalidated or replaced in the background.
+//
+// If WithNotFoundTTL is used, not found errors are cached and returned again
+// until they expire.
+//
+// Errors from the store are treated as cache misses, so that a broken store
+// degrades to searching for every package.
+//
+// Concurrent searches for the same uncached module are coalesced into a single
+// search, whose result or error is returned to every caller. A caller whose
+// context is done stops waiting, while the search continues for the others. The
+// search is cancelled once every caller has stopped waiting.
+//
+// The returned package is a deep copy of the cached one, so callers are free to
+// modify it.
 func (c *cachedSearcher) Search(ctx context.Context, module string) (Package, error) {
+	path, version := SplitVersion(module)
+	key := JoinVersion(path, version)
+
+	if pkg, stored, created, ok := c.get(key); ok {
+		switch {
+		case !c.expired(created, time.Now()):
+			c.hits.Add(1)
+			return pkg, nil
+		case c.config.staleWhileRevalidate:
+			c.hits.Add(1)
+			if stored != key {
+				// an alias is refreshed through the entry it points to.
+				module = stored
+			}
+			c.refresh(module, path, stored, pkg.Clone())
+			return pkg, nil
+		}
+	}
+	if err := c.getNotFound(key); err != nil {
+		c.hits.Add(1)
+		return Package{}, err
+	}
+	c.misses.Add(1)
+
+	// concurrent misses for the same key share a single search. The search is
+	// detached from the context of the caller that started it, so that it is
+	// not cancelled for everyone else when that caller gives up.
+	fctx, leave := c.join(ctx, key)
+	defer leave()
+	ch := c.share(fctx, module, path, key)
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return Package{}, res.Err
+		}
+		// every caller gets its own copy, as the result is shared with the
+		// other callers and the store.
+		return res.Val.(Package).Clone(), nil
+	case <-ctx.Done():
+		return Package{}, ctx.Err()
+	}
+}
+
+// get returns a copy of the package cached under key, either directly or
+// through an alias, along with the key it is stored under and when it was
+// created. The entry is marked as recently used.
+func (c *cachedSearcher) get(key string) (Package, string, time.Time, bool) {
 	c.mu.RLock()
-	cPkg, ok := c.cache[module]
+	stored := key
+	cPkg, ok, err := c.store.Get(key)
+	if err == nil && !ok {
+		if alias, found := c.aliases[key]; found {
+			stored = alias
+			cPkg, ok, err = c.store.Get(alias)
+		}
+	}
+	if err != nil || !ok {
+		c.mu.RUnlock()
+		return Package{}, "", time.Time{}, false
+	}
+	pkg, created := cPkg.Package.Clone(), cPkg.Created
 	c.mu.RUnlock()
-	if ok {
-		cPkg.Updated = time.Now()
-		return cPkg.Package, nil
+
+	c.mu.Lock()
+	c.store.Touch(stored, time.Now())
+	c.mu.Unlock()
+	return pkg, stored, created, true
+}
+
+// refresh fetches the module in the background, unless it is already being
+// refreshed or the searcher is closed. If the refreshed package differs from
+// the stale one, the refresh hook is called.
+func (c *cachedSearcher) refresh(module, path, key string, stale Package) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.refreshing[key] {
+		return
 	}
+	c.refreshing[key] = true
 
-	pkg, err := c.Searcher.Search(ctx, module)
-	if err != nil {
+	c.refreshes.Add(1)
+	go func() {
+		defer c.refreshes.Done()
+
+		fctx, leave := c.join(c.ctx, key)
+		ch := c.share(fctx, module, path, key)
+
+		var res singleflight.Result
+		select {
+		case res = <-ch:
+		case <-c.ctx.Done():
+			res.Err = c.ctx.Err()
+		}
+		leave()
+
+		c.mu.Lock()
+		delete(c.refreshing, key)
+		c.mu.Unlock()
+
+		if res.Err != nil || c.config.onRefresh == nil {
+			return
+		}
+		if fresh := res.Val.(Package); !reflect.DeepEqual(stale, fresh) {
+			c.config.onRefresh(module, stale, fresh.Clone())
+		}
+	}()
+}
+
+// share fetches the module, sharing the search with every other caller for
+// key. A panic of the searcher is returned as a *PanicError, as singleflight
+// would otherwise panic again in a goroutine that no caller can recover.
+func (c *cachedSearcher) share(ctx context.Context, module, path, key string) <-chan singleflight.Result {
+	return c.group.DoChan(key, func() (pkg any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				pkg, err = nil, &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+		return c.fetch(ctx, module, path, key)
+	})
+}
+
+// flight is a fetch shared by the searches waiting for the same key.
+type flight struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// join registers a caller waiting for the fetch of key, and returns the context
+// the fetch uses, along with a function to call once the caller stops waiting.
+// The context keeps the values of ctx, but is only cancelled once every caller
+// has stopped waiting.
+func (c *cachedSearcher) join(ctx context.Context, key string) (context.Context, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.flights[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{ctx: fctx, cancel: cancel}
+		c.flights[key] = f
+	}
+	f.waiters++
+
+	var once sync.Once
+	return f.ctx, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			f.waiters--
+			if f.waiters > 0 {
+				return
+			}
+			f.cancel()
+			delete(c.flights, key)
+			// new callers start a new fetch instead of sharing the
+			// cancelled one.
+			c.group.Forget(key)
+		})
+	}
+}
+
+// getNotFound returns the cached not found error for key, if it has not
+// expired.
+func (c *cachedSearcher) getNotFound(key string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if e, ok := c.notFound[key]; ok && time.Now().Before(e.expires) {
+		return e.err
+	}
+	return nil
+}
+
+// fetch searches for the module and stores the result under key, with the
+// resolved version of the package as an alias. If an expired entry is still
+// stored under key, it is revalidated instead.
+func (c *cachedSearcher) fetch(ctx context.Context, module, path, key string) (Package, error) {
+	c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+
+	c.mu.RLock()
+	old, ok, err := c.store.Get(key)
+	c.mu.RUnlock()
+
+	var v validators
+	if err == nil && ok {
+		v = validators{etag: old.ETag, lastModified: old.LastModified}
+	}
+
+	pkg, v, err := c.search(ctx, module, v)
+	switch {
+	case errors.Is(err, errNotModified) && ok && old != nil:
+		pkg, v = old.Package, validators{etag: old.ETag, lastModified: old.LastModified}
+	case err != nil:
+		if c.config.notFoundTTL > 0 && errors.Is(err, ErrNotFound) {
+			c.mu.Lock()
+			c.notFound[key] = notFoundEntry{err: err, expires: time.Now().Add(c.config.notFoundTTL)}
+			c.mu.Unlock()
+		}
 		return Package{}, err
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cache[module] = &CachedPackage{
-		Package: pkg,
-		Created: time.Now(),
-		Updated: time.Now(),
+	cPkg := &CachedPackage{
+		Package:      pkg,
+		Created:      time.Now(),
+		Updated:      time.Now(),
+		ETag:         v.etag,
+		LastModified: v.lastModified,
 	}
+	delete(c.notFound, key)
+	c.store.Put(key, cPkg)
+	c.unalias(key)
+	delete(c.aliases, key)
+	if resolved := JoinVersion(path, pkg.Version); resolved != key {
+		c.aliases[resolved] = key
+	}
+	c.evict()
 	return pkg, nil
 }
 
+// search uses a conditional request if the underlying searcher supports it.
+func (c *cachedSearcher) search(ctx context.Context, module string, v validators) (Package, validators, error) {
+	if s, ok := c.Searcher.(conditionalSearcher); ok {
+		return s.searchConditional(ctx, module, v)
+	}
+	pkg, err := c.Searcher.Search(ctx, module)
+	return pkg, validators{}, err
+}
+
+// WithCache calls f with the contents of the cache. With the default memory
+// store, f operates on the store directly. For other stores, all packages are
+// loaded into a map, and changes made by f are written back to the store once
+// it returns. Only packages that f added, replaced, or whose Created or Updated
+// time it changed are written back, and removed packages are deleted.
 func (c *cachedSearcher) WithCache(f func(cache map[string]*CachedPackage)) {
 	c.mu.Lock()
-	f(c.cache)
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+
+	if m, ok := c.store.(memoryStore); ok {
+		f(m)
+		return
+	}
+
+	entries, err := c.store.Entries()
+	if err != nil {
+		return
+	}
+	cache := make(map[string]*CachedPackage, len(entries))
+	loaded := make(map[string]loadedEntry, len(entries))
+	for _, e := range entries {
+		if cPkg, ok, err := c.store.Get(e.Key); err == nil && ok {
+			cache[e.Key] = cPkg
+			loaded[e.Key] = loadedEntry{cPkg, cPkg.Created, cPkg.Updated}
+		}
+	}
+
+	f(cache)
+
+	for key := range loaded {
+		if _, ok := cache[key]; !ok {
+			c.delete(key)
+		}
+	}
+	for key, cPkg := range cache {
+		if l, ok := loaded[key]; ok && l.unchanged(cPkg) {
+			continue
+		}
+		c.store.Put(key, cPkg)
+	}
+}
+
+// loadedEntry is a package loaded from the store by WithCache, used to find
+// the packages that were changed.
+type loadedEntry struct {
+	pkg     *CachedPackage
+	created time.Time
+	updated time.Time
+}
+
+// unchanged reports whether cPkg is the loaded package, with the same times.
+func (l loadedEntry) unchanged(cPkg *CachedPackage) bool {
+	return l.pkg == cPkg && l.created.Equal(cPkg.Created) && l.updated.Equal(cPkg.Updated)
+}
+
+// Close stops the janitor, if one was started with WithJanitor, cancels any
+// background refreshes and waits for them to exit. The cache can still be used
+// after it is closed, but expired entries will no longer be refreshed in the
+// background.
+func (c *cachedSearcher) Close() error {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		c.mu.Unlock()
+		c.cancel()
+		close(c.stop)
+	})
+	<-c.done
+	c.refreshes.Wait()
+	return nil
+}
+
+// expired reports whether an entry created at created is older than the
+// maximum age.
+func (c *cachedSearcher) expired(created, now time.Time) bool {
+	return c.config.maxAge > 0 && now.Sub(created) > c.config.maxAge
+}
+
+// evict removes the least recently used entries, by their Updated time, until
+// the cache holds no more than the maximum number of entries. c.mu must be held.
+func (c *cachedSearcher) evict() {
+	if c.config.maxEntries <= 0 {
+		return
+	}
+
+	entries, err := c.store.Entries()
+	if err != nil || len(entries) <= c.config.maxEntries {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Updated.Before(entries[j].Updated)
+	})
+	for _, e := range entries[:len(entries)-c.config.maxEntries] {
+		if c.delete(e.Key) == nil {
+			c.evictions.Add(1)
+		}
+	}
+}
+
+// delete removes the package stored under key, along with its aliases. c.mu
+// must be held.
+func (c *cachedSearcher) delete(key string) error {
+	c.unalias(key)
+	return c.store.Delete(key)
+}
+
+// unalias removes the aliases pointing to key. c.mu must be held.
+func (c *cachedSearcher) unalias(key string) {
+	for alias, k := range c.aliases {
+		if k == key {
+			delete(c.aliases, alias)
+		}
+	}
+}
+
+// prune removes all expired entries from the cache, and evicts entries over the
+// maximum number of entries.
+func (c *cachedSearcher) prune() {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.store.Entries()
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if c.expired(e.Created, now) && c.delete(e.Key) == nil {
+			c.evictions.Add(1)
+		}
+	}
+	for key, e := range c.notFound {
+		if now.After(e.expires) {
+			delete(c.notFound, key)
+		}
+	}
+	c.evict()
+}
+
+// janitor periodically prunes the cache until ctx is done or the searcher is
+// closed.
+func (c *cachedSearcher) janitor(ctx context.Context, interval time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.prune()
+		case <-ctx.Done():
+			return
+		case <-c.stop:
+			return
+		}
+	}
 }
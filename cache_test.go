@@ -0,0 +1,576 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// countingSearcher returns a small package named after the module, and counts
+// how many times it was called.
+type countingSearcher struct {
+	calls atomic.Int64
+}
+
+func (s *countingSearcher) Search(_ context.Context, module string) (Package, error) {
+	s.calls.Add(1)
+	return Package{
+		Name:      module,
+		Overview:  Comment{Paragraph("Package " + module + ".")},
+		Functions: map[string]Function{"f": {Name: "F"}},
+	}, nil
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	s := &countingSearcher{}
+	c := newCachedSearcher(s, cacheConfig{maxEntries: 2})
+	defer c.Close()
+
+	c.Search(ctx, "a")
+	c.Search(ctx, "b")
+	c.Search(ctx, "a")
+	c.Search(ctx, "c") // evicts b, the least recently used entry
+
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		if len(cache) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(cache))
+		}
+		if _, ok := cache["b"]; ok {
+			t.Errorf("expected b to be evicted")
+		}
+	})
+}
+
+// versionedSearcher resolves modules without a version to v1.0.0.
+type versionedSearcher struct {
+	countingSearcher
+}
+
+func (s *versionedSearcher) Search(ctx context.Context, module string) (Package, error) {
+	path, version := SplitVersion(module)
+	pkg, err := s.countingSearcher.Search(ctx, path)
+	pkg.Version = version
+	if version == "" {
+		pkg.Version = "v1.0.0"
+	}
+	return pkg, err
+}
+
+func TestCacheMaxEntriesVersioned(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create store: %v", err)
+	}
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "file": store} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := &versionedSearcher{}
+			c := newCachedSearcher(s, cacheConfig{store: store, maxEntries: 1})
+			defer c.Close()
+
+			for _, module := range []string{"a", "a", "a@latest", "a@v1.0.0"} {
+				if pkg, err := c.Search(ctx, module); err != nil || pkg.Version != "v1.0.0" {
+					t.Fatalf("could not search %q: %v, %q", module, err, pkg.Version)
+				}
+			}
+			stats := c.Stats()
+			want := CacheStats{Hits: 3, Misses: 1, Entries: 1, Size: stats.Size}
+			if stats != want {
+				t.Errorf("unexpected stats: got %+v, want %+v", stats, want)
+			}
+
+			// evicting a also removes its resolved version.
+			c.Search(ctx, "b")
+			c.Search(ctx, "a@v1.0.0")
+			if n := s.calls.Load(); n != 3 {
+				t.Errorf("expected 3 searches, got %d", n)
+			}
+			if entries := c.Entries(); len(entries) != 1 || entries[0].Key != "a@v1.0.0" {
+				t.Errorf("unexpected entries: %+v", entries)
+			}
+		})
+	}
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	ctx := context.Background()
+	s := &countingSearcher{}
+	c := newCachedSearcher(s, cacheConfig{maxAge: time.Hour})
+	defer c.Close()
+
+	c.Search(ctx, "a")
+	c.Search(ctx, "a")
+	if n := s.calls.Load(); n != 1 {
+		t.Errorf("expected 1 search, got %d", n)
+	}
+
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["a"].Created = time.Now().Add(-2 * time.Hour)
+	})
+	c.Search(ctx, "a")
+	if n := s.calls.Load(); n != 2 {
+		t.Errorf("expected expired entry to be searched again, got %d searches", n)
+	}
+}
+
+func TestCacheJanitor(t *testing.T) {
+	ctx := context.Background()
+	s := &countingSearcher{}
+	c := newCachedSearcher(s, cacheConfig{
+		maxAge:          time.Hour,
+		janitorCtx:      ctx,
+		janitorInterval: time.Millisecond,
+	})
+
+	c.Search(ctx, "a")
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["a"].Created = time.Now().Add(-2 * time.Hour)
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		var n int
+		c.WithCache(func(cache map[string]*CachedPackage) { n = len(cache) })
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("janitor did not remove expired entry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("could not close cache: %v", err)
+	}
+}
+
+// blockingSearcher blocks every search until release is closed.
+type blockingSearcher struct {
+	countingSearcher
+	release chan struct{}
+}
+
+func (s *blockingSearcher) Search(ctx context.Context, module string) (Package, error) {
+	<-s.release
+	return s.countingSearcher.Search(ctx, module)
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	s := &blockingSearcher{release: make(chan struct{})}
+	c := newCachedSearcher(s, cacheConfig{})
+	defer c.Close()
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Search(context.Background(), "bytes")
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, c, "bytes", n)
+
+	// a caller that gives up should not affect the others.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := c.Search(ctx, "bytes")
+		cancelled <- err
+	}()
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancelled caller to return context.Canceled, got %v", err)
+	}
+
+	close(s.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if calls := s.calls.Load(); calls != 1 {
+		t.Errorf("expected 1 search, got %d", calls)
+	}
+}
+
+// waitForWaiters waits until n callers are waiting for the fetch of key.
+func waitForWaiters(t *testing.T, c *cachedSearcher, key string, n int) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		f := c.flights[key]
+		waiting := f != nil && f.waiters == n
+		c.mu.Unlock()
+		if waiting {
+			return
+		}
+	}
+	t.Fatalf("expected %d callers to wait for %s", n, key)
+}
+
+// hangingSearcher blocks until its context is done, and reports when that
+// happens.
+type hangingSearcher struct {
+	started chan struct{}
+	done    chan struct{}
+}
+
+func (s *hangingSearcher) Search(ctx context.Context, module string) (Package, error) {
+	close(s.started)
+	<-ctx.Done()
+	close(s.done)
+	return Package{}, ctx.Err()
+}
+
+func TestCacheSingleflightCancel(t *testing.T) {
+	s := &hangingSearcher{started: make(chan struct{}), done: make(chan struct{})}
+	c := newCachedSearcher(s, cacheConfig{})
+	defer c.Close()
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel2()
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.Search(ctx1, "bytes")
+		errs <- err
+	}()
+	<-s.started
+	go func() {
+		_, err := c.Search(ctx2, "bytes")
+		errs <- err
+	}()
+
+	// the fetch continues while the first caller is still waiting.
+	if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected second caller to time out, got %v", err)
+	}
+	select {
+	case <-s.done:
+		t.Fatalf("expected fetch to continue while a caller is waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel1()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected first caller to be cancelled, got %v", err)
+	}
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected fetch to be cancelled once every caller stopped waiting")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c := newCachedSearcher(&countingSearcher{}, cacheConfig{maxEntries: 2})
+	defer c.Close()
+
+	c.Search(ctx, "b")
+	c.Search(ctx, "a")
+	c.Search(ctx, "a")
+	c.Search(ctx, "c") // evicts b
+
+	stats := c.Stats()
+	want := CacheStats{Hits: 1, Misses: 3, Evictions: 1, Entries: 2, Size: stats.Size}
+	if stats != want {
+		t.Errorf("unexpected stats: got %+v, want %+v", stats, want)
+	}
+	if stats.Size <= 0 {
+		t.Errorf("expected a positive size, got %d", stats.Size)
+	}
+
+	entries := c.Entries()
+	if len(entries) != 2 || entries[0].Key != "a" || entries[1].Key != "c" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestCacheStatsShared(t *testing.T) {
+	c := newCachedSearcher(&countingSearcher{}, cacheConfig{})
+	defer c.Close()
+
+	c.Search(context.Background(), "a")
+	single := c.Stats().Size
+
+	// a package stored under two keys counts as two entries, in both the
+	// number of entries and their size.
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["b"] = cache["a"]
+	})
+	stats := c.Stats()
+	if stats.Entries != 2 || len(c.Entries()) != 2 {
+		t.Errorf("expected 2 entries, got %d and %+v", stats.Entries, c.Entries())
+	}
+	if stats.Size != 2*single {
+		t.Errorf("expected size %d, got %d", 2*single, stats.Size)
+	}
+}
+
+func TestCacheIsolation(t *testing.T) {
+	ctx := context.Background()
+	c := newCachedSearcher(&countingSearcher{}, cacheConfig{})
+	defer c.Close()
+
+	for range 2 {
+		pkg, err := c.Search(ctx, "bytes")
+		if err != nil {
+			t.Fatalf("could not search: %v", err)
+		}
+		if _, ok := pkg.Functions["f"]; !ok || len(pkg.Functions) != 1 || len(pkg.Overview) != 1 {
+			t.Fatalf("cached package was modified: %+v", pkg)
+		}
+
+		delete(pkg.Functions, "f")
+		pkg.Functions["g"] = Function{Name: "G"}
+		pkg.Overview[0] = Paragraph("modified")
+		pkg.Overview = append(pkg.Overview, Paragraph("appended"))
+	}
+}
+
+// TestCacheRace is meant to be run with the race detector, and exercises all
+// methods of the cache concurrently while callers modify their results.
+func TestCacheRace(t *testing.T) {
+	ctx := context.Background()
+	c := newCachedSearcher(&countingSearcher{}, cacheConfig{
+		maxEntries:      2,
+		janitorCtx:      ctx,
+		janitorInterval: time.Millisecond,
+	})
+	defer c.Close()
+
+	modules := []string{"a", "b", "c"}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				pkg, err := c.Search(ctx, modules[(i+j)%len(modules)])
+				if err != nil {
+					t.Errorf("could not search: %v", err)
+					return
+				}
+				pkg.Functions["g"] = Function{Name: "G"}
+				pkg.Overview[0] = Paragraph("modified")
+
+				c.Stats()
+				c.Entries()
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			c.WithCache(func(cache map[string]*CachedPackage) {
+				for _, cPkg := range cache {
+					_ = cPkg.Updated
+				}
+			})
+		}
+	}()
+	wg.Wait()
+
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		for key, cPkg := range cache {
+			if _, ok := cPkg.Functions["g"]; ok || cPkg.Overview[0] != Paragraph("Package "+key+".") {
+				t.Errorf("cached package %s was modified by a caller", key)
+			}
+		}
+	})
+}
+
+// stubParser parses the title of a page served by an httptest.Server.
+type stubParser struct {
+	base string
+}
+
+func (p stubParser) URL(module string) string {
+	return p.base + "/" + module
+}
+
+func (p stubParser) Parse(document *goquery.Document, _, _ bool) (Package, error) {
+	return Package{Name: document.Find("title").Text()}, nil
+}
+
+func TestCacheNotFound(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	s := newHTTPSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithNotFoundTTL(time.Hour))
+	c := newCachedSearcher(s, s.cache)
+	defer c.Close()
+
+	for range 3 {
+		_, err := c.Search(context.Background(), "github.com/missing/pkg")
+		if !errors.Is(err, InvalidStatusError(404)) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	var requests, notModified atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("<html><head><title>pkg</title></head></html>"))
+	}))
+	defer srv.Close()
+
+	s := newHTTPSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithMaxAge(time.Hour))
+	c := newCachedSearcher(s, s.cache)
+	defer c.Close()
+
+	ctx := context.Background()
+	if _, err := c.Search(ctx, "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["pkg"].Created = time.Now().Add(-2 * time.Hour)
+	})
+
+	pkg, err := c.Search(ctx, "pkg")
+	if err != nil {
+		t.Fatalf("could not revalidate: %v", err)
+	}
+	if pkg.Name != "pkg" {
+		t.Errorf("expected cached package to be reused, got %+v", pkg)
+	}
+	if requests.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("expected a single conditional request, got %d requests and %d not modified",
+			requests.Load(), notModified.Load())
+	}
+
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		if time.Since(cache["pkg"].Created) > time.Minute {
+			t.Errorf("expected revalidated entry to be refreshed")
+		}
+	})
+}
+
+func TestCacheUnconditionalNotModified(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()))
+	if _, err := s.Search(context.Background(), "pkg"); !errors.Is(err, InvalidStatusError(304)) {
+		t.Errorf("expected not modified status error, got %v", err)
+	}
+
+	c := NewCachedSearcher(stubParser{srv.URL}, WithClient(srv.Client()))
+	defer c.Close()
+	if _, err := c.Search(context.Background(), "pkg"); !errors.Is(err, InvalidStatusError(304)) {
+		t.Errorf("expected not modified status error, got %v", err)
+	}
+}
+
+// changingSearcher returns a different package every time it is called.
+type changingSearcher struct {
+	calls atomic.Int64
+}
+
+func (s *changingSearcher) Search(_ context.Context, module string) (Package, error) {
+	n := s.calls.Add(1)
+	return Package{Name: module, Version: fmt.Sprintf("v1.0.%d", n)}, nil
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	refreshed := make(chan [2]Package, 1)
+	c := newCachedSearcher(&changingSearcher{}, cacheConfig{
+		maxAge:               time.Hour,
+		staleWhileRevalidate: true,
+		onRefresh: func(module string, stale, fresh Package) {
+			refreshed <- [2]Package{stale, fresh}
+		},
+	})
+	defer c.Close()
+
+	if _, err := c.Search(ctx, "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["pkg"].Created = time.Now().Add(-2 * time.Hour)
+	})
+
+	pkg, err := c.Search(ctx, "pkg")
+	if err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if pkg.Version != "v1.0.1" {
+		t.Errorf("expected stale package to be returned, got version %q", pkg.Version)
+	}
+
+	select {
+	case pkgs := <-refreshed:
+		if pkgs[0].Version != "v1.0.1" || pkgs[1].Version != "v1.0.2" {
+			t.Errorf("unexpected refresh: stale %q, fresh %q", pkgs[0].Version, pkgs[1].Version)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("refresh hook was not called")
+	}
+
+	pkg, _ = c.Search(ctx, "pkg")
+	if pkg.Version != "v1.0.2" {
+		t.Errorf("expected refreshed package to be cached, got version %q", pkg.Version)
+	}
+}
+
+func TestCacheStaleWhileRevalidateClosed(t *testing.T) {
+	ctx := context.Background()
+	s := &changingSearcher{}
+	c := newCachedSearcher(s, cacheConfig{maxAge: time.Hour, staleWhileRevalidate: true})
+
+	if _, err := c.Search(ctx, "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["pkg"].Created = time.Now().Add(-2 * time.Hour)
+	})
+	c.Close()
+
+	pkg, err := c.Search(ctx, "pkg")
+	if err != nil || pkg.Version != "v1.0.1" {
+		t.Fatalf("expected stale package after close, got %q, %v", pkg.Version, err)
+	}
+	c.Close()
+	if n := s.calls.Load(); n != 1 {
+		t.Errorf("expected no refresh after close, got %d searches", n)
+	}
+}
@@ -0,0 +1,159 @@
+package doc
+
+// Clone returns a deep copy of the package. Modifying the maps, slices or
+// comments of the copy does not affect the original.
+func (p Package) Clone() Package {
+	c := p
+	c.Overview = p.Overview.Clone()
+	c.Examples = cloneSlice(p.Examples)
+	c.Constants = cloneVariables(p.Constants)
+	c.Variables = cloneVariables(p.Variables)
+	c.ConstantMap = cloneMap(p.ConstantMap, Variable.clone)
+	c.VariableMap = cloneMap(p.VariableMap, Variable.clone)
+	c.Functions = cloneMap(p.Functions, Function.clone)
+	c.Types = cloneMap(p.Types, Type.clone)
+	c.Subpackages = cloneSlice(p.Subpackages)
+	c.Diagnostics = cloneSlice(p.Diagnostics)
+	return c
+}
+
+// Clone returns a deep copy of the comment.
+func (c Comment) Clone() Comment {
+	if c == nil {
+		return nil
+	}
+
+	notes := make(Comment, len(c))
+	for i, n := range c {
+		notes[i] = cloneNote(n)
+	}
+	return notes
+}
+
+// cloneNote copies notes that contain references to other notes. All other
+// notes are immutable values, and are returned as is.
+func cloneNote(n Note) Note {
+	switch n := n.(type) {
+	case Comment:
+		return n.Clone()
+	case RichText:
+		return RichText(Comment(n).Clone())
+	case List:
+		return n.clone()
+	case ListItem:
+		return n.clone()
+	default:
+		return n
+	}
+}
+
+func (l List) clone() List {
+	if l.Items != nil {
+		items := make([]ListItem, len(l.Items))
+		for i, item := range l.Items {
+			items[i] = item.clone()
+		}
+		l.Items = items
+	}
+	return l
+}
+
+func (item ListItem) clone() ListItem {
+	item.Content = item.Content.Clone()
+	return item
+}
+
+func (v Variable) clone() Variable {
+	v.Comment = v.Comment.Clone()
+	return v
+}
+
+func (f Function) clone() Function {
+	f.Parsed = f.Parsed.clone()
+	f.Comment = f.Comment.Clone()
+	f.Examples = cloneSlice(f.Examples)
+	return f
+}
+
+func (s *Signature) clone() *Signature {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Receiver != nil {
+		recv := *s.Receiver
+		c.Receiver = &recv
+	}
+	c.TypeParams = cloneSlice(s.TypeParams)
+	c.Params = cloneSlice(s.Params)
+	c.Results = cloneSlice(s.Results)
+	return &c
+}
+
+func (m Method) clone() Method {
+	m.Function = m.Function.clone()
+	return m
+}
+
+func (t Type) clone() Type {
+	t.Comment = t.Comment.Clone()
+	t.Examples = cloneSlice(t.Examples)
+	t.Fields = cloneFields(t.Fields)
+	t.InterfaceMethods = cloneInterfaceMethods(t.InterfaceMethods)
+	t.TypeFunctions = cloneMap(t.TypeFunctions, Function.clone)
+	t.Methods = cloneMap(t.Methods, Method.clone)
+	return t
+}
+
+func cloneFields(fields []Field) []Field {
+	if fields == nil {
+		return nil
+	}
+	c := make([]Field, len(fields))
+	for i, f := range fields {
+		f.Comment = f.Comment.Clone()
+		c[i] = f
+	}
+	return c
+}
+
+func cloneInterfaceMethods(methods []InterfaceMethod) []InterfaceMethod {
+	if methods == nil {
+		return nil
+	}
+	c := make([]InterfaceMethod, len(methods))
+	for i, m := range methods {
+		m.Comment = m.Comment.Clone()
+		c[i] = m
+	}
+	return c
+}
+
+func cloneVariables(vars []Variable) []Variable {
+	if vars == nil {
+		return nil
+	}
+	c := make([]Variable, len(vars))
+	for i, v := range vars {
+		c[i] = v.clone()
+	}
+	return c
+}
+
+func cloneSlice[V any](s []V) []V {
+	if s == nil {
+		return nil
+	}
+	return append(make([]V, 0, len(s)), s...)
+}
+
+func cloneMap[V any](m map[string]V, clone func(V) V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]V, len(m))
+	for k, v := range m {
+		c[k] = clone(v)
+	}
+	return c
+}
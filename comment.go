@@ -0,0 +1,305 @@
+package doc
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	_ Note = RichText(nil)
+	_ Note = Plain("")
+	_ Note = Code("")
+	_ Note = Link{}
+	_ Note = DocLink{}
+	_ Note = List{}
+	_ Note = ListItem{}
+)
+
+// RichText is a paragraph made of inline notes, such as Plain, Code, Link and
+// DocLink. Parsers use a Paragraph instead when a paragraph only contains
+// plain text.
+type RichText []Note
+
+func (r RichText) Text() string {
+	var sb strings.Builder
+	for _, n := range r {
+		sb.WriteString(n.Text())
+	}
+	return sb.String()
+}
+
+func (r RichText) HTML() string {
+	return "<p>" + r.inlineHTML() + "</p>"
+}
+
+func (r RichText) inlineHTML() string {
+	var sb strings.Builder
+	for _, n := range r {
+		sb.WriteString(n.HTML())
+	}
+	return sb.String()
+}
+
+func (r RichText) Markdown() string {
+	var sb strings.Builder
+	for _, n := range r {
+		sb.WriteString(n.Markdown())
+	}
+	return sb.String()
+}
+
+// NewParagraph creates a paragraph from inline notes, for use by parsers.
+// Whitespace in Plain notes is collapsed, and trimmed at the start and end of
+// the paragraph. If only plain text remains, a Paragraph is returned,
+// otherwise a RichText.
+func NewParagraph(notes ...Note) Note {
+	var rich RichText
+	for _, n := range notes {
+		p, ok := n.(Plain)
+		if !ok {
+			rich = append(rich, n)
+			continue
+		}
+		if last, ok := lastPlain(rich); ok {
+			p = last + p
+			rich = rich[:len(rich)-1]
+		}
+		rich = append(rich, Plain(collapse(string(p))))
+	}
+	if p, ok := firstPlain(rich); ok {
+		rich[0] = Plain(strings.TrimLeftFunc(string(p), unicode.IsSpace))
+	}
+	if p, ok := lastPlain(rich); ok {
+		rich[len(rich)-1] = Plain(strings.TrimRightFunc(string(p), unicode.IsSpace))
+	}
+
+	// drop plain text that became empty after trimming.
+	result := rich[:0]
+	for _, n := range rich {
+		if p, ok := n.(Plain); ok && p == "" {
+			continue
+		}
+		result = append(result, n)
+	}
+
+	switch len(result) {
+	case 0:
+		return Paragraph("")
+	case 1:
+		if p, ok := result[0].(Plain); ok {
+			return Paragraph(p)
+		}
+	}
+	return result
+}
+
+// Plain is plain text inside of a RichText paragraph.
+type Plain string
+
+func (p Plain) Text() string {
+	return string(p)
+}
+
+func (p Plain) HTML() string {
+	return html.EscapeString(string(p))
+}
+
+func (p Plain) Markdown() string {
+	return string(p)
+}
+
+// Code is an inline code span inside of a RichText paragraph.
+type Code string
+
+func (c Code) Text() string {
+	return string(c)
+}
+
+func (c Code) HTML() string {
+	return "<code>" + html.EscapeString(string(c)) + "</code>"
+}
+
+func (c Code) Markdown() string {
+	return "`" + string(c) + "`"
+}
+
+// Link is a hyperlink inside of a RichText paragraph.
+type Link struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+func (l Link) Text() string {
+	return l.Label
+}
+
+func (l Link) HTML() string {
+	return `<a href="` + html.EscapeString(l.URL) + `">` + html.EscapeString(l.Label) + "</a>"
+}
+
+func (l Link) Markdown() string {
+	return "[" + l.Label + "](" + l.URL + ")"
+}
+
+// DocLink is a link to the documentation of a package or symbol, written as
+// [pkg.Symbol] or [Symbol] in doc comments.
+type DocLink struct {
+	Label string `json:"label"`
+	// ImportPath is the import path of the linked package. It is empty for
+	// links to symbols in the same package.
+	ImportPath string `json:"import_path"`
+	// Recv is the receiver type of a linked method, without the pointer.
+	Recv string `json:"recv"`
+	// Name is the name of the linked symbol. It is empty for links to
+	// packages.
+	Name string `json:"name"`
+	// URL is the url of the linked documentation, if known.
+	URL string `json:"url"`
+}
+
+func (l DocLink) Text() string {
+	return l.Label
+}
+
+func (l DocLink) HTML() string {
+	if l.URL == "" {
+		return Code(l.Label).HTML()
+	}
+	return Link{Label: l.Label, URL: l.URL}.HTML()
+}
+
+func (l DocLink) Markdown() string {
+	if l.URL == "" {
+		return Code(l.Label).Markdown()
+	}
+	return Link{Label: l.Label, URL: l.URL}.Markdown()
+}
+
+// List is a bullet or numbered list.
+type List struct {
+	Ordered bool       `json:"ordered"`
+	Items   []ListItem `json:"items"`
+}
+
+func (l List) Text() string {
+	items := make([]string, 0, len(l.Items))
+	for i, item := range l.Items {
+		items = append(items, l.marker(i, item)+" "+indent(item.Text(), "  "))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (l List) HTML() string {
+	tag := "ul"
+	if l.Ordered {
+		tag = "ol"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<" + tag + ">\n")
+	for _, item := range l.Items {
+		sb.WriteString(item.HTML() + "\n")
+	}
+	sb.WriteString("</" + tag + ">")
+	return sb.String()
+}
+
+func (l List) Markdown() string {
+	items := make([]string, 0, len(l.Items))
+	for i, item := range l.Items {
+		marker := l.marker(i, item)
+		items = append(items, marker+" "+indent(item.Markdown(), strings.Repeat(" ", len(marker)+1)))
+	}
+	return strings.Join(items, "\n")
+}
+
+// marker returns the bullet or number of the i-th item in the list.
+func (l List) marker(i int, item ListItem) string {
+	if !l.Ordered {
+		return "-"
+	}
+	if item.Number != "" {
+		return item.Number + "."
+	}
+	return strconv.Itoa(i+1) + "."
+}
+
+// ListItem is an item in a List.
+type ListItem struct {
+	// Number is the number of the item in an ordered list, such as "1".
+	Number  string  `json:"number"`
+	Content Comment `json:"content"`
+}
+
+func (item ListItem) Text() string {
+	return item.Content.Text()
+}
+
+// HTML returns the item as an <li> element. An item containing a single
+// paragraph is rendered without the surrounding <p>.
+func (item ListItem) HTML() string {
+	if len(item.Content) == 1 {
+		switch n := item.Content[0].(type) {
+		case Paragraph:
+			return "<li>" + html.EscapeString(string(n)) + "</li>"
+		case RichText:
+			return "<li>" + n.inlineHTML() + "</li>"
+		}
+	}
+	return "<li>" + item.Content.HTML() + "</li>"
+}
+
+func (item ListItem) Markdown() string {
+	return item.Content.Markdown()
+}
+
+// indent indents all but the first line of s with prefix. Empty lines are
+// left empty.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapse replaces each run of whitespace in s with a single space.
+func collapse(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+func firstPlain(r RichText) (Plain, bool) {
+	if len(r) == 0 {
+		return "", false
+	}
+	p, ok := r[0].(Plain)
+	return p, ok
+}
+
+func lastPlain(r RichText) (Plain, bool) {
+	if len(r) == 0 {
+		return "", false
+	}
+	p, ok := r[len(r)-1].(Plain)
+	return p, ok
+}
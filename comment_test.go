@@ -0,0 +1,90 @@
+package doc_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hhhapz/doc"
+)
+
+var richComment = doc.Comment{
+	doc.RichText{
+		doc.Plain("Use "),
+		doc.Code("Do"),
+		doc.Plain(" with a "),
+		doc.DocLink{Label: "http.Server", ImportPath: "net/http", Name: "Server", URL: "https://pkg.go.dev/net/http#Server"},
+		doc.Plain(", see "),
+		doc.Link{Label: "the docs", URL: "https://go.dev/doc?a=1&b=2"},
+		doc.Plain("."),
+	},
+	doc.List{Items: []doc.ListItem{
+		{Content: doc.Comment{doc.Paragraph("one")}},
+		{Content: doc.Comment{doc.RichText{doc.Plain("two "), doc.Code("x < y")}}},
+	}},
+	doc.List{Ordered: true, Items: []doc.ListItem{
+		{Number: "1", Content: doc.Comment{doc.Paragraph("first")}},
+		{Number: "2", Content: doc.Comment{doc.Paragraph("second"), doc.Paragraph("more")}},
+	}},
+}
+
+func TestRichCommentText(t *testing.T) {
+	want := "Use Do with a http.Server, see the docs.\n\n" +
+		"- one\n- two x < y\n\n" +
+		"1. first\n2. second\n\n  more"
+	if got := richComment.Text(); got != want {
+		t.Errorf("unexpected text:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestRichCommentHTML(t *testing.T) {
+	want := `<p>Use <code>Do</code> with a <a href="https://pkg.go.dev/net/http#Server">http.Server</a>, see <a href="https://go.dev/doc?a=1&amp;b=2">the docs</a>.</p>
+<ul>
+<li>one</li>
+<li>two <code>x &lt; y</code></li>
+</ul>
+<ol>
+<li>first</li>
+<li><p>second</p>
+<p>more</p></li>
+</ol>`
+	if got := richComment.HTML(); got != want {
+		t.Errorf("unexpected html:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRichCommentMarkdown(t *testing.T) {
+	want := "Use `Do` with a [http.Server](https://pkg.go.dev/net/http#Server), see [the docs](https://go.dev/doc?a=1&b=2).\n\n" +
+		"- one\n- two `x < y`\n\n" +
+		"1. first\n2. second\n\n   more"
+	if got := richComment.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestNewParagraph(t *testing.T) {
+	if got := doc.NewParagraph(doc.Plain("  a\n  b "), doc.Plain(" c\t")); got != doc.Paragraph("a b c") {
+		t.Errorf("expected plain paragraph, got %#v", got)
+	}
+
+	got := doc.NewParagraph(doc.Plain("\n"), doc.Code("x"), doc.Plain("  and\n"), doc.Code("y"), doc.Plain(" "))
+	want := doc.RichText{doc.Code("x"), doc.Plain(" and "), doc.Code("y")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
+
+func TestRichCommentClone(t *testing.T) {
+	c := richComment.Clone()
+	if !reflect.DeepEqual(c, richComment) {
+		t.Fatalf("expected clone to equal the original")
+	}
+
+	c[0].(doc.RichText)[0] = doc.Plain("changed")
+	c[1].(doc.List).Items[0].Content[0] = doc.Paragraph("changed")
+	if richComment[0].(doc.RichText)[0] != doc.Plain("Use ") {
+		t.Errorf("modifying the clone changed the rich text of the original")
+	}
+	if richComment[1].(doc.List).Items[0].Content[0] != doc.Paragraph("one") {
+		t.Errorf("modifying the clone changed the list of the original")
+	}
+}
@@ -0,0 +1,275 @@
+package doc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TypeKind is the kind of a type declaration, as found in Type.Type. It is
+// derived from the declaration, and empty if the declaration could not be
+// parsed.
+type TypeKind string
+
+const (
+	StructType    TypeKind = "struct"
+	InterfaceType TypeKind = "interface"
+	// AliasType is an alias declaration, such as "type A = B".
+	AliasType   TypeKind = "alias"
+	FuncType    TypeKind = "func"
+	MapType     TypeKind = "map"
+	SliceType   TypeKind = "slice"
+	ArrayType   TypeKind = "array"
+	ChanType    TypeKind = "chan"
+	PointerType TypeKind = "pointer"
+	// BasicType is a type defined from a basic type or another named type,
+	// such as "type Duration int64" or "type IntSet Set[int]".
+	BasicType TypeKind = "basic"
+	// GenericType is a type declared with type parameters, such as
+	// "type Set[T comparable] map[T]struct{}", whatever its underlying type.
+	GenericType TypeKind = "generic"
+)
+
+// TypesOfKind returns all types in the package of the given kind, sorted by
+// name.
+func (p Package) TypesOfKind(kind TypeKind) []Type {
+	var types []Type
+	for _, t := range p.Types {
+		if t.Type == kind {
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
+// Field is a field of a struct type.
+type Field struct {
+	// Name is the name of the field. For embedded fields, it is the name of
+	// the embedded type, without the package or pointer.
+	Name string `json:"name"`
+	// Type is the type of the field, as written in the declaration.
+	Type string `json:"type"`
+	// Tag is the unquoted struct tag of the field.
+	Tag      string  `json:"tag"`
+	Embedded bool    `json:"embedded"`
+	Comment  Comment `json:"comment"`
+}
+
+// InterfaceMethod is a method or embedded type in the declaration of an
+// interface type.
+type InterfaceMethod struct {
+	// Name is the name of the method. For embedded types, it is the name of
+	// the type, and empty for unions and other type constraints.
+	Name string `json:"name"`
+	// Signature is the signature of the method without the func keyword, such
+	// as "Read(p []byte) (n int, err error)", or the embedded type.
+	Signature string  `json:"signature"`
+	Embedded  bool    `json:"embedded"`
+	Comment   Comment `json:"comment"`
+}
+
+// TypeDecl is the parsed declaration of a type.
+type TypeDecl struct {
+	Kind TypeKind
+	// Fields are the fields of a struct type.
+	Fields []Field
+	// InterfaceMethods are the methods and embedded types of an interface
+	// type.
+	InterfaceMethods []InterfaceMethod
+}
+
+// ParseTypeDecl parses the declaration of the type name, as found in
+// Type.Signature, and returns its kind, and its fields if it is a struct type
+// or its methods if it is an interface type. Comments of fields and methods
+// inside of the declaration are kept.
+//
+// An error is returned if the declaration is not valid Go source. If the
+// declaration does not contain a type with the given name, an empty TypeDecl
+// is returned.
+func ParseTypeDecl(name, signature string) (TypeDecl, error) {
+	fset, spec, err := parseTypeSpec(name, signature)
+	if err != nil || spec == nil {
+		return TypeDecl{}, err
+	}
+
+	var decl TypeDecl
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		decl.Fields = structFields(fset, t)
+	case *ast.InterfaceType:
+		decl.InterfaceMethods = interfaceMethods(fset, t)
+	}
+	switch {
+	case spec.Assign.IsValid():
+		decl.Kind = AliasType
+	case spec.TypeParams != nil:
+		decl.Kind = GenericType
+	default:
+		decl.Kind = typeKind(spec.Type)
+	}
+	return decl, nil
+}
+
+// ParseType sets the kind, fields and interface methods of t from its
+// signature using ParseTypeDecl, for use by parsers. If the signature is not a
+// valid declaration, an InvalidDeclaration diagnostic for selector is added to
+// the package instead.
+func (p *Package) ParseType(t *Type, selector string) {
+	if t.Signature == "" {
+		return
+	}
+	decl, err := ParseTypeDecl(t.Name, t.Signature)
+	if err != nil {
+		p.Diagnose(InvalidDeclaration, selector, err.Error())
+		return
+	}
+	t.Type = decl.Kind
+	t.Fields = decl.Fields
+	t.InterfaceMethods = decl.InterfaceMethods
+}
+
+func typeKind(expr ast.Expr) TypeKind {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return typeKind(e.X)
+	case *ast.StructType:
+		return StructType
+	case *ast.InterfaceType:
+		return InterfaceType
+	case *ast.FuncType:
+		return FuncType
+	case *ast.MapType:
+		return MapType
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return SliceType
+		}
+		return ArrayType
+	case *ast.ChanType:
+		return ChanType
+	case *ast.StarExpr:
+		return PointerType
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		return BasicType
+	}
+	return ""
+}
+
+// parseTypeSpec parses signature, and returns the spec of the type name in it.
+func parseTypeSpec(name, signature string) (*token.FileSet, *ast.TypeSpec, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", "package p\n"+signature, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fset, typeSpec(file, name), nil
+}
+
+// typeSpec returns the spec of the type name in file.
+func typeSpec(file *ast.File, name string) *ast.TypeSpec {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if spec, ok := spec.(*ast.TypeSpec); ok && spec.Name.Name == name {
+				return spec
+			}
+		}
+	}
+	return nil
+}
+
+func structFields(fset *token.FileSet, t *ast.StructType) []Field {
+	var fields []Field
+	for _, f := range t.Fields.List {
+		field := Field{
+			Type:    printExpr(fset, f.Type),
+			Comment: fieldComment(f),
+		}
+		if f.Tag != nil {
+			field.Tag, _ = strconv.Unquote(f.Tag.Value)
+		}
+
+		if len(f.Names) == 0 {
+			field.Name = embeddedName(f.Type)
+			field.Embedded = true
+			fields = append(fields, field)
+			continue
+		}
+		for _, name := range f.Names {
+			field.Name = name.Name
+			field.Comment = field.Comment.Clone()
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func interfaceMethods(fset *token.FileSet, t *ast.InterfaceType) []InterfaceMethod {
+	var methods []InterfaceMethod
+	for _, f := range t.Methods.List {
+		m := InterfaceMethod{Comment: fieldComment(f)}
+		fn, ok := f.Type.(*ast.FuncType)
+		if len(f.Names) == 0 || !ok {
+			m.Name = embeddedName(f.Type)
+			m.Signature = printExpr(fset, f.Type)
+			m.Embedded = true
+			methods = append(methods, m)
+			continue
+		}
+
+		m.Name = f.Names[0].Name
+		m.Signature = m.Name + strings.TrimPrefix(printExpr(fset, fn), "func")
+		methods = append(methods, m)
+	}
+	return methods
+}
+
+// fieldComment returns the doc comment of f, or its line comment if it has
+// no doc comment.
+func fieldComment(f *ast.Field) Comment {
+	group := f.Doc
+	if group == nil {
+		group = f.Comment
+	}
+	if group == nil {
+		return nil
+	}
+	return parseComment(group.Text())
+}
+
+// embeddedName returns the name of an embedded type, or an empty string if
+// expr is not a named type.
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return ""
+}
+
+func printExpr(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,132 @@
+package doc_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hhhapz/doc"
+)
+
+func TestParseTypeDecl(t *testing.T) {
+	decl, err := doc.ParseTypeDecl("List", "type List[T any] struct {\n\tio.Writer\n\t*Node[T]\n\tlen, cap int\n}")
+	if err != nil {
+		t.Fatalf("could not parse fields: %v", err)
+	}
+	want := []doc.Field{
+		{Name: "Writer", Type: "io.Writer", Embedded: true},
+		{Name: "Node", Type: "*Node[T]", Embedded: true},
+		{Name: "len", Type: "int"},
+		{Name: "cap", Type: "int"},
+	}
+	if !reflect.DeepEqual(decl.Fields, want) || decl.InterfaceMethods != nil {
+		t.Errorf("unexpected fields %#v and methods %#v", decl.Fields, decl.InterfaceMethods)
+	}
+	if decl.Kind != doc.GenericType {
+		t.Errorf("expected generic kind, got %q", decl.Kind)
+	}
+
+	decl, err = doc.ParseTypeDecl("Number", "type Number interface {\n\t~int | ~float64\n\t// String formats the number.\n\tString() string\n}")
+	if err != nil {
+		t.Fatalf("could not parse methods: %v", err)
+	}
+	wantMethods := []doc.InterfaceMethod{
+		{Signature: "~int | ~float64", Embedded: true},
+		{Name: "String", Signature: "String() string", Comment: doc.Comment{doc.Paragraph("String formats the number.")}},
+	}
+	if !reflect.DeepEqual(decl.InterfaceMethods, wantMethods) || decl.Kind != doc.InterfaceType {
+		t.Errorf("unexpected %q methods %#v", decl.Kind, decl.InterfaceMethods)
+	}
+}
+
+func TestParseTypeDeclGrouped(t *testing.T) {
+	decl, err := doc.ParseTypeDecl("B", "type (\n\tA struct{ X int }\n\tB struct{ Y string }\n)")
+	if err != nil {
+		t.Fatalf("could not parse fields: %v", err)
+	}
+	if want := []doc.Field{{Name: "Y", Type: "string"}}; !reflect.DeepEqual(decl.Fields, want) {
+		t.Errorf("unexpected fields %#v", decl.Fields)
+	}
+}
+
+func TestParseTypeDeclOther(t *testing.T) {
+	for _, signature := range []string{"type T int", "type T = map[string]int", "type U struct{ X int }"} {
+		decl, err := doc.ParseTypeDecl("T", signature)
+		if err != nil || decl.Fields != nil || decl.InterfaceMethods != nil {
+			t.Errorf("expected no fields or methods for %q, got %v, %v, %v", signature, decl.Fields, decl.InterfaceMethods, err)
+		}
+	}
+
+	if _, err := doc.ParseTypeDecl("T", "type T struct {"); err == nil {
+		t.Errorf("expected error for invalid declaration")
+	}
+}
+
+func TestParseTypeDeclKind(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      doc.TypeKind
+	}{
+		{"type T struct{ X int }", doc.StructType},
+		{"type T interface{ Run() }", doc.InterfaceType},
+		{"type T = map[string]int", doc.AliasType},
+		{"type T func(int) error", doc.FuncType},
+		{"type T map[string]int", doc.MapType},
+		{"type T []byte", doc.SliceType},
+		{"type T [4]byte", doc.ArrayType},
+		{"type T chan<- int", doc.ChanType},
+		{"type T *int", doc.PointerType},
+		{"type T int64", doc.BasicType},
+		{"type T time.Duration", doc.BasicType},
+		{"type T Set[int]", doc.BasicType},
+		{"type T[K comparable, V any] struct{ m map[K]V }", doc.GenericType},
+		{"type (\n\tU int\n\tT (string)\n)", doc.BasicType},
+		{"type U int", ""},
+	}
+	for _, tt := range tests {
+		decl, err := doc.ParseTypeDecl("T", tt.signature)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", tt.signature, err)
+			continue
+		}
+		if decl.Kind != tt.want {
+			t.Errorf("expected %q to be %q, got %q", tt.signature, tt.want, decl.Kind)
+		}
+	}
+}
+
+func TestPackageParseType(t *testing.T) {
+	var pkg doc.Package
+	typ := doc.Type{Name: "T", Signature: "type T struct {\n\tX int\n}"}
+	pkg.ParseType(&typ, "#T")
+	if typ.Type != doc.StructType || len(typ.Fields) != 1 || len(pkg.Diagnostics) != 0 {
+		t.Errorf("unexpected type %+v with diagnostics %v", typ, pkg.Diagnostics)
+	}
+
+	typ = doc.Type{Name: "T", Signature: "type T struct {"}
+	pkg.ParseType(&typ, "#T")
+	if typ.Type != "" || typ.Fields != nil {
+		t.Errorf("expected invalid declaration to be left unparsed, got %+v", typ)
+	}
+	if len(pkg.Diagnostics) != 1 || pkg.Diagnostics[0].Kind != doc.InvalidDeclaration || pkg.Diagnostics[0].Selector != "#T" {
+		t.Errorf("expected an invalid declaration diagnostic for #T, got %v", pkg.Diagnostics)
+	}
+}
+
+func TestTypesOfKind(t *testing.T) {
+	pkg := doc.Package{Types: map[string]doc.Type{
+		"writer": {Name: "Writer", Type: doc.InterfaceType},
+		"buffer": {Name: "Buffer", Type: doc.StructType},
+		"reader": {Name: "Reader", Type: doc.InterfaceType},
+	}}
+
+	var names []string
+	for _, typ := range pkg.TypesOfKind(doc.InterfaceType) {
+		names = append(names, typ.Name)
+	}
+	if want := []string{"Reader", "Writer"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+	if types := pkg.TypesOfKind(doc.MapType); len(types) != 0 {
+		t.Errorf("expected no map types, got %v", types)
+	}
+}
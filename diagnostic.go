@@ -0,0 +1,97 @@
+package doc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiagnosticKind is the kind of problem described by a Diagnostic.
+type DiagnosticKind int
+
+const (
+	// MissingSection indicates that a section the parser expects on every
+	// page could not be found.
+	MissingSection DiagnosticKind = iota
+	// EmptySignature indicates that a declaration had no name or signature.
+	EmptySignature
+	// UnrecognizedNode indicates that an element was skipped because the
+	// parser does not know how to handle it.
+	UnrecognizedNode
+	// InvalidDeclaration indicates that a declaration could not be parsed as
+	// Go source, so its fields, methods or parsed signature are missing.
+	InvalidDeclaration
+)
+
+// String returns the name of the kind, such as "missing section".
+func (k DiagnosticKind) String() string {
+	switch k {
+	case MissingSection:
+		return "missing section"
+	case EmptySignature:
+		return "empty signature"
+	case UnrecognizedNode:
+		return "unrecognized node"
+	case InvalidDeclaration:
+		return "invalid declaration"
+	default:
+		return fmt.Sprintf("DiagnosticKind(%d)", int(k))
+	}
+}
+
+// Diagnostic describes something a parser skipped or could not find while
+// parsing a page, without failing the parse. Diagnostics usually mean that the
+// markup of the site has changed.
+type Diagnostic struct {
+	Kind DiagnosticKind `json:"kind"`
+	// Selector is the selector of the element the diagnostic is about.
+	Selector string `json:"selector"`
+	// Message describes the problem in more detail.
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic as "kind: selector: message".
+func (d Diagnostic) String() string {
+	s := d.Kind.String()
+	if d.Selector != "" {
+		s += ": " + d.Selector
+	}
+	if d.Message != "" {
+		s += ": " + d.Message
+	}
+	return s
+}
+
+// Diagnose records something that was skipped or could not be found while
+// parsing the package, for use by parsers.
+func (p *Package) Diagnose(kind DiagnosticKind, selector, msg string) {
+	p.Diagnostics = append(p.Diagnostics, Diagnostic{
+		Kind:     kind,
+		Selector: selector,
+		Message:  msg,
+	})
+}
+
+// DiagnosticsError is returned by searchers created with Strict when the
+// parsed package has diagnostics.
+type DiagnosticsError struct {
+	Module      string
+	Diagnostics []Diagnostic
+}
+
+// Error satisfies the error interface.
+func (err *DiagnosticsError) Error() string {
+	msgs := make([]string, 0, len(err.Diagnostics))
+	for _, d := range err.Diagnostics {
+		msgs = append(msgs, d.String())
+	}
+	return fmt.Sprintf("%s: %d parse diagnostics: %s", err.Module, len(err.Diagnostics), strings.Join(msgs, "; "))
+}
+
+// Strict makes the searcher return a *DiagnosticsError instead of the package
+// when the parser reported any diagnostics, to notice changes to the markup of
+// the site early.
+func Strict() SearchOption {
+	return func(s *httpSearcher) {
+		s.strict = true
+	}
+}
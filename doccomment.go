@@ -0,0 +1,71 @@
+package doc
+
+import "go/doc/comment"
+
+// NewComment converts a doc comment parsed by go/doc/comment into a Comment,
+// for use by parsers working from source. Italic text is kept as plain text,
+// and doc links are not given a URL.
+func NewComment(d *comment.Doc) Comment {
+	if d == nil || len(d.Content) == 0 {
+		return nil
+	}
+	return commentBlocks(d.Content)
+}
+
+// parseComment parses the text of a comment, such as the comment of a struct
+// field, without resolving doc links to symbols in other packages.
+func parseComment(text string) Comment {
+	var p comment.Parser
+	return NewComment(p.Parse(text))
+}
+
+func commentBlocks(content []comment.Block) Comment {
+	comments := make(Comment, 0, len(content))
+	for _, block := range content {
+		switch b := block.(type) {
+		case *comment.Paragraph:
+			comments = append(comments, NewParagraph(commentText(b.Text)...))
+		case *comment.Code:
+			comments = append(comments, Pre(b.Text))
+		case *comment.Heading:
+			comments = append(comments, Heading(NewParagraph(commentText(b.Text)...).Text()))
+		case *comment.List:
+			list := List{Ordered: len(b.Items) > 0 && b.Items[0].Number != ""}
+			for _, item := range b.Items {
+				list.Items = append(list.Items, ListItem{
+					Number:  item.Number,
+					Content: commentBlocks(item.Content),
+				})
+			}
+			comments = append(comments, list)
+		}
+	}
+	return comments
+}
+
+// commentText converts the inline elements of a doc comment into notes. Italic text
+// is kept as plain text.
+func commentText(text []comment.Text) []Note {
+	notes := make([]Note, 0, len(text))
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			notes = append(notes, Plain(t))
+		case comment.Italic:
+			notes = append(notes, Plain(t))
+		case *comment.Link:
+			notes = append(notes, Link{
+				Label: NewParagraph(commentText(t.Text)...).Text(),
+				URL:   t.URL,
+			})
+		case *comment.DocLink:
+			notes = append(notes, DocLink{
+				Label:      NewParagraph(commentText(t.Text)...).Text(),
+				ImportPath: t.ImportPath,
+				Recv:       t.Recv,
+				Name:       t.Name,
+			})
+		}
+	}
+	return notes
+}
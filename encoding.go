@@ -0,0 +1,240 @@
+package doc
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON encoding of a Package. It is
+// incremented whenever a change to the encoding would prevent an older version
+// of this package from decoding it, such as a renamed field or note type.
+const SchemaVersion = 1
+
+func init() {
+	// Notes are stored in interfaces, so their types must be registered to
+	// be encoded with gob.
+	gob.Register(Comment(nil))
+	gob.Register(Heading(""))
+	gob.Register(Paragraph(""))
+	gob.Register(Pre(""))
+	gob.Register(RichText(nil))
+	gob.Register(Plain(""))
+	gob.Register(Code(""))
+	gob.Register(Link{})
+	gob.Register(DocLink{})
+	gob.Register(List{})
+	gob.Register(ListItem{})
+}
+
+// UnsupportedSchemaError is returned when decoding a Package that was encoded
+// with a newer schema version than SchemaVersion.
+type UnsupportedSchemaError int
+
+func (e UnsupportedSchemaError) Error() string {
+	return fmt.Sprintf("unsupported package schema version %d, expected at most %d", int(e), SchemaVersion)
+}
+
+// MarshalJSON encodes the package, setting the schema version to
+// SchemaVersion if it is not set.
+func (p Package) MarshalJSON() ([]byte, error) {
+	type plain Package
+	if p.SchemaVersion == 0 {
+		p.SchemaVersion = SchemaVersion
+	}
+	return json.Marshal(plain(p))
+}
+
+// UnmarshalJSON decodes the package. An *UnsupportedSchemaError is returned if
+// the package was encoded with a newer schema version.
+func (p *Package) UnmarshalJSON(data []byte) error {
+	type plain Package
+	var version struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &version); err != nil {
+		return err
+	}
+	if version.SchemaVersion > SchemaVersion {
+		return UnsupportedSchemaError(version.SchemaVersion)
+	}
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// cachedPackageFields are the fields of a CachedPackage encoded alongside those
+// of its package.
+type cachedPackageFields struct {
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+// MarshalJSON encodes the package like Package.MarshalJSON, with the created
+// and updated times and the validators of the cached package added to the same
+// object.
+func (c CachedPackage) MarshalJSON() ([]byte, error) {
+	data, err := c.Package.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := json.Marshal(cachedPackageFields{
+		Created:      c.Created,
+		Updated:      c.Updated,
+		ETag:         c.ETag,
+		LastModified: c.LastModified,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fields, &obj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON decodes a cached package encoded by MarshalJSON. Like
+// Package.UnmarshalJSON, an *UnsupportedSchemaError is returned if the package
+// was encoded with a newer schema version.
+func (c *CachedPackage) UnmarshalJSON(data []byte) error {
+	var pkg Package
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return err
+	}
+	var fields cachedPackageFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*c = CachedPackage{
+		Package:      pkg,
+		Created:      fields.Created,
+		Updated:      fields.Updated,
+		ETag:         fields.ETag,
+		LastModified: fields.LastModified,
+	}
+	return nil
+}
+
+// jsonNote is the JSON encoding of a Note. Type is the name of the note type,
+// and Value is the JSON encoding of the note itself.
+type jsonNote struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// noteDecoders maps the name of every note type to a function decoding it.
+var noteDecoders = map[string]func([]byte) (Note, error){
+	"comment":   decodeNote[Comment],
+	"heading":   decodeNote[Heading],
+	"paragraph": decodeNote[Paragraph],
+	"pre":       decodeNote[Pre],
+	"rich_text": decodeNote[RichText],
+	"plain":     decodeNote[Plain],
+	"code":      decodeNote[Code],
+	"link":      decodeNote[Link],
+	"doc_link":  decodeNote[DocLink],
+	"list":      decodeNote[List],
+	"list_item": decodeNote[ListItem],
+}
+
+func decodeNote[T Note](data []byte) (Note, error) {
+	var n T
+	err := json.Unmarshal(data, &n)
+	return n, err
+}
+
+// noteType returns the name of the type of n, as used in noteDecoders.
+func noteType(n Note) (string, bool) {
+	switch n.(type) {
+	case Comment:
+		return "comment", true
+	case Heading:
+		return "heading", true
+	case Paragraph:
+		return "paragraph", true
+	case Pre:
+		return "pre", true
+	case RichText:
+		return "rich_text", true
+	case Plain:
+		return "plain", true
+	case Code:
+		return "code", true
+	case Link:
+		return "link", true
+	case DocLink:
+		return "doc_link", true
+	case List:
+		return "list", true
+	case ListItem:
+		return "list_item", true
+	}
+	return "", false
+}
+
+// MarshalJSON encodes the comment as an array of objects, each with the type
+// of the note, such as "paragraph", and its value:
+//
+//	[{"type": "paragraph", "value": "Package doc ..."}]
+func (c Comment) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return []byte("null"), nil
+	}
+
+	notes := make([]jsonNote, 0, len(c))
+	for _, n := range c {
+		typ, ok := noteType(n)
+		if !ok {
+			return nil, fmt.Errorf("cannot encode note of type %T", n)
+		}
+		value, err := json.Marshal(n)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, jsonNote{Type: typ, Value: value})
+	}
+	return json.Marshal(notes)
+}
+
+// UnmarshalJSON decodes a comment encoded by MarshalJSON.
+func (c *Comment) UnmarshalJSON(data []byte) error {
+	var notes []jsonNote
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return err
+	}
+	if notes == nil {
+		*c = nil
+		return nil
+	}
+
+	comment := make(Comment, 0, len(notes))
+	for _, n := range notes {
+		decode, ok := noteDecoders[n.Type]
+		if !ok {
+			return fmt.Errorf("cannot decode note of unknown type %q", n.Type)
+		}
+		note, err := decode(n.Value)
+		if err != nil {
+			return fmt.Errorf("decoding %s note: %w", n.Type, err)
+		}
+		comment = append(comment, note)
+	}
+	*c = comment
+	return nil
+}
+
+// MarshalJSON encodes the notes of the paragraph like a Comment.
+func (r RichText) MarshalJSON() ([]byte, error) {
+	return Comment(r).MarshalJSON()
+}
+
+// UnmarshalJSON decodes a paragraph encoded by MarshalJSON.
+func (r *RichText) UnmarshalJSON(data []byte) error {
+	return (*Comment)(r).UnmarshalJSON(data)
+}
@@ -0,0 +1,134 @@
+package doc_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hhhapz/doc"
+)
+
+var encodedPackage = doc.Package{
+	URL:      "example.com/pkg",
+	Name:     "pkg",
+	Version:  "v1.2.3",
+	Source:   "pkg.go.dev",
+	Overview: append(doc.Comment{doc.Heading("Usage"), doc.Pre("pkg.Do()\n"), doc.Comment{doc.Paragraph("nested")}}, richComment...),
+	Examples: []doc.Example{{Name: "Do", Code: "pkg.Do()", Output: "done"}},
+	Functions: map[string]doc.Function{
+		"do": {Name: "Do", Signature: "func Do()", Comment: doc.Comment{doc.Paragraph("Do does things.")}},
+	},
+	Types: map[string]doc.Type{
+		"t": {
+			Name:      "T",
+			Signature: "type T struct{}",
+			Comment:   doc.Comment{doc.ListItem{Number: "1", Content: doc.Comment{doc.Code("x")}}},
+			Methods: map[string]doc.Method{
+				"run": {For: "T", Function: doc.Function{Name: "Run", Comment: doc.Comment{doc.RichText{doc.DocLink{Label: "Run", Name: "Run"}}}}},
+			},
+		},
+	},
+	Diagnostics: []doc.Diagnostic{{Kind: doc.UnrecognizedNode, Selector: "h4", Message: "missing id"}},
+}
+
+func TestPackageJSON(t *testing.T) {
+	data, err := json.Marshal(encodedPackage)
+	if err != nil {
+		t.Fatalf("could not encode package: %v", err)
+	}
+
+	var pkg doc.Package
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		t.Fatalf("could not decode package: %v", err)
+	}
+	want := encodedPackage
+	want.SchemaVersion = doc.SchemaVersion
+	if !reflect.DeepEqual(pkg, want) {
+		t.Errorf("decoded package does not match:\n%#v\nwant:\n%#v", pkg, want)
+	}
+}
+
+func TestCachedPackageJSON(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cPkg := doc.CachedPackage{
+		Package:      encodedPackage,
+		Created:      created,
+		Updated:      created.Add(time.Hour),
+		ETag:         `"abc"`,
+		LastModified: "Tue, 02 Jan 2024 03:04:05 GMT",
+	}
+	data, err := json.Marshal(cPkg)
+	if err != nil {
+		t.Fatalf("could not encode cached package: %v", err)
+	}
+
+	var decoded doc.CachedPackage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("could not decode cached package: %v", err)
+	}
+	want := cPkg
+	want.SchemaVersion = doc.SchemaVersion
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("decoded cached package does not match:\n%#v\nwant:\n%#v", decoded, want)
+	}
+
+	// the cached package can still be decoded as a package.
+	var pkg doc.Package
+	if err := json.Unmarshal(data, &pkg); err != nil || !reflect.DeepEqual(pkg, want.Package) {
+		t.Errorf("could not decode cached package as a package: %v", err)
+	}
+
+	data = bytes.Replace(data, []byte(`"schema_version":1`), []byte(`"schema_version":99`), 1)
+	var unsupported doc.UnsupportedSchemaError
+	if err := json.Unmarshal(data, &decoded); !errors.As(err, &unsupported) {
+		t.Errorf("expected unsupported schema error, got %v", err)
+	}
+}
+
+func TestCommentJSON(t *testing.T) {
+	data, err := json.Marshal(doc.Comment{doc.Paragraph("a"), doc.RichText{doc.Code("b")}})
+	if err != nil {
+		t.Fatalf("could not encode comment: %v", err)
+	}
+	want := `[{"type":"paragraph","value":"a"},{"type":"rich_text","value":[{"type":"code","value":"b"}]}]`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	var c doc.Comment
+	if err := json.Unmarshal([]byte(`[{"type":"unknown","value":""}]`), &c); err == nil {
+		t.Errorf("expected error decoding unknown note type")
+	}
+}
+
+func TestPackageJSONSchema(t *testing.T) {
+	var pkg doc.Package
+	err := json.Unmarshal([]byte(`{"schema_version":1000,"name":"pkg"}`), &pkg)
+	var schemaErr doc.UnsupportedSchemaError
+	if !errors.As(err, &schemaErr) || schemaErr != 1000 {
+		t.Errorf("expected unsupported schema error, got %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"pkg"}`), &pkg); err != nil || pkg.Name != "pkg" {
+		t.Errorf("expected package without a schema version to decode, got %v", err)
+	}
+}
+
+func TestPackageGob(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(encodedPackage); err != nil {
+		t.Fatalf("could not encode package: %v", err)
+	}
+
+	var pkg doc.Package
+	if err := gob.NewDecoder(&buf).Decode(&pkg); err != nil {
+		t.Fatalf("could not decode package: %v", err)
+	}
+	if !reflect.DeepEqual(pkg, encodedPackage) {
+		t.Errorf("decoded package does not match:\n%#v\nwant:\n%#v", pkg, encodedPackage)
+	}
+}
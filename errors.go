@@ -0,0 +1,94 @@
+package doc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// ErrNotFound indicates that the requested package does not exist, either
+	// because the site responded with a 404 status, or served a not found
+	// page.
+	ErrNotFound = errors.New("package not found")
+	// ErrRateLimited indicates that the site responded with a 429 status.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUpstream indicates that the site failed to serve the page, and
+	// responded with a 5xx status.
+	ErrUpstream = errors.New("upstream error")
+)
+
+// InvalidStatusError indicates that the request to the documentation site was
+// not successful. The value is the status that was returned from the page
+// instead.
+//
+// Using errors.Is, an InvalidStatusError matches ErrNotFound for 404 and 410
+// statuses, ErrRateLimited for 429 statuses, and ErrUpstream for 5xx statuses.
+type InvalidStatusError int
+
+// Error satisfies the error interface.
+func (err InvalidStatusError) Error() string {
+	return fmt.Sprintf("invalid response status: %d", err)
+}
+
+// Is reports whether the status belongs to the category of target.
+func (err InvalidStatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return err == 404 || err == 410
+	case ErrRateLimited:
+		return err == 429
+	case ErrUpstream:
+		return err >= 500 && err < 600
+	default:
+		return false
+	}
+}
+
+// ParseError is returned by parsers when a documentation page could not be
+// parsed.
+type ParseError struct {
+	// Parser is the name of the parser, such as "godocs" or "pkgsite".
+	Parser string
+	// Module is the module that was being parsed. It is set by searchers.
+	Module string
+	// Selector is the selector of the element being parsed, if any.
+	Selector string
+	// Sel is the selection being parsed, if any.
+	Sel *goquery.Selection
+	// Message describes what went wrong.
+	Message string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (err *ParseError) Error() string {
+	var b strings.Builder
+	if err.Parser != "" {
+		b.WriteString(err.Parser + ": ")
+	}
+	if err.Module != "" {
+		b.WriteString("parsing " + err.Module + ": ")
+	}
+	if err.Selector != "" {
+		b.WriteString(err.Selector + ": ")
+	}
+
+	switch {
+	case err.Message != "" && err.Err != nil:
+		b.WriteString(err.Message + ": " + err.Err.Error())
+	case err.Err != nil:
+		b.WriteString(err.Err.Error())
+	default:
+		b.WriteString(err.Message)
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
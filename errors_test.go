@@ -0,0 +1,102 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestInvalidStatusErrorIs(t *testing.T) {
+	tests := []struct {
+		status InvalidStatusError
+		target error
+		want   bool
+	}{
+		{404, ErrNotFound, true},
+		{410, ErrNotFound, true},
+		{429, ErrRateLimited, true},
+		{500, ErrUpstream, true},
+		{503, ErrUpstream, true},
+		{403, ErrNotFound, false},
+		{404, ErrUpstream, false},
+		{429, ErrUpstream, false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(fmt.Errorf("wrapped: %w", tt.status), tt.target); got != tt.want {
+			t.Errorf("errors.Is(%d, %v) = %v, want %v", tt.status, tt.target, got, tt.want)
+		}
+	}
+}
+
+// failingParser always fails with a *ParseError.
+type failingParser struct {
+	stubParser
+}
+
+func (failingParser) Parse(document *goquery.Document, _, _ bool) (Package, error) {
+	return Package{}, &ParseError{Parser: "failing", Selector: "title", Message: "could not parse"}
+}
+
+func TestParseErrorModule(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html></html>")
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(failingParser{stubParser{srv.URL}}, WithClient(srv.Client()))
+	_, err := s.Search(context.Background(), "example.com/pkg")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if parseErr.Module != "example.com/pkg" {
+		t.Errorf("expected module to be set, got %q", parseErr.Module)
+	}
+	if want := "failing: parsing example.com/pkg: title: could not parse"; err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err.Error())
+	}
+}
+
+// diagnosingParser parses the title of a page, and reports a diagnostic.
+type diagnosingParser struct {
+	stubParser
+}
+
+func (diagnosingParser) Parse(document *goquery.Document, _, _ bool) (Package, error) {
+	return Package{
+		Name:        document.Find("title").Text(),
+		Diagnostics: []Diagnostic{{Kind: MissingSection, Selector: "#pkg-index"}},
+	}, nil
+}
+
+func TestStrict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>pkg</title></head></html>")
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(diagnosingParser{stubParser{srv.URL}}, WithClient(srv.Client()))
+	pkg, err := s.Search(context.Background(), "pkg")
+	if err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if len(pkg.Diagnostics) != 1 {
+		t.Errorf("expected 1 diagnostic, got %v", pkg.Diagnostics)
+	}
+
+	s = NewSearcher(diagnosingParser{stubParser{srv.URL}}, WithClient(srv.Client()), Strict())
+	_, err = s.Search(context.Background(), "pkg")
+	var diagErr *DiagnosticsError
+	if !errors.As(err, &diagErr) || len(diagErr.Diagnostics) != 1 {
+		t.Fatalf("expected diagnostics error, got %v", err)
+	}
+	if want := "pkg: 1 parse diagnostics: missing section: #pkg-index"; err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err.Error())
+	}
+}
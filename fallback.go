@@ -0,0 +1,117 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNoSearchers is returned by a fallback searcher that has no searchers to
+// try.
+var ErrNoSearchers = errors.New("no searchers to fall back to")
+
+// FallbackPolicy decides whether a fallback searcher should try the next
+// searcher after a search failed with err.
+type FallbackPolicy func(err error) bool
+
+// DefaultFallbackPolicy falls through to the next searcher on ErrNotFound,
+// ErrRateLimited and ErrUpstream errors, on a *ParseError, and on any other
+// errors that may be specific to one site, such as ErrVersionUnsupported or
+// network errors.
+//
+// It does not fall through when the context is done, when a short package name
+// is ambiguous, or on other unsuccessful statuses.
+func DefaultFallbackPolicy(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var ambiguous *AmbiguousPackageError
+	if errors.As(err, &ambiguous) {
+		return false
+	}
+
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstream) {
+		return true
+	}
+	var status InvalidStatusError
+	return !errors.As(err, &status)
+}
+
+// FallbackError is returned by a fallback searcher when every searcher failed.
+// It contains the error of each searcher, in order.
+type FallbackError struct {
+	Errors []error
+}
+
+// Error satisfies the error interface.
+func (err *FallbackError) Error() string {
+	msgs := make([]string, 0, len(err.Errors))
+	for _, e := range err.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	return "all searchers failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of all searchers, so that errors.Is and errors.As
+// match if any of the searchers failed with the target error.
+func (err *FallbackError) Unwrap() []error {
+	return err.Errors
+}
+
+// fallbackSearcher tries each searcher in order until one succeeds.
+type fallbackSearcher struct {
+	searchers []Searcher
+	policy    FallbackPolicy
+}
+
+// fallbackSearcher implements the Searcher interface.
+var _ Searcher = fallbackSearcher{}
+
+// NewFallbackSearcher creates a Searcher that tries each of the searchers in
+// order, returning the first successful result. After a failed search, policy
+// decides whether the next searcher is tried, or the error is returned as is.
+// If policy is nil, DefaultFallbackPolicy is used.
+//
+// The searcher that produced the result is reported by Package.Source.
+func NewFallbackSearcher(policy FallbackPolicy, searchers ...Searcher) Searcher {
+	if policy == nil {
+		policy = DefaultFallbackPolicy
+	}
+	return fallbackSearcher{
+		searchers: searchers,
+		policy:    policy,
+	}
+}
+
+// NewParserFallback creates a fallback searcher with DefaultFallbackPolicy,
+// that tries a searcher for each of the parsers in order. All searchers are
+// created with the same options.
+func NewParserFallback(parsers []Parser, opts ...SearchOption) Searcher {
+	searchers := make([]Searcher, 0, len(parsers))
+	for _, p := range parsers {
+		searchers = append(searchers, NewSearcher(p, opts...))
+	}
+	return NewFallbackSearcher(nil, searchers...)
+}
+
+// Search tries each searcher in order, until one succeeds or the policy stops
+// falling through. If all searchers fail, a *FallbackError is returned.
+func (f fallbackSearcher) Search(ctx context.Context, module string) (Package, error) {
+	if len(f.searchers) == 0 {
+		return Package{}, ErrNoSearchers
+	}
+
+	errs := make([]error, 0, len(f.searchers))
+	for _, s := range f.searchers {
+		pkg, err := s.Search(ctx, module)
+		if err == nil {
+			return pkg, nil
+		}
+		if !f.policy(err) {
+			return Package{}, err
+		}
+		errs = append(errs, err)
+	}
+	return Package{}, &FallbackError{Errors: errs}
+}
@@ -0,0 +1,83 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>pkg</title></head></html>")
+	}))
+	defer up.Close()
+
+	s := NewParserFallback([]Parser{stubParser{down.URL}, stubParser{up.URL}}, WithClient(up.Client()))
+	pkg, err := s.Search(context.Background(), "pkg")
+	if err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if pkg.Name != "pkg" {
+		t.Errorf("expected package pkg, got %q", pkg.Name)
+	}
+
+	u, _ := url.Parse(up.URL)
+	if pkg.Source != u.Host {
+		t.Errorf("expected source %q, got %q", u.Host, pkg.Source)
+	}
+}
+
+type errSearcher struct {
+	err   error
+	calls *int
+}
+
+func (s errSearcher) Search(context.Context, string) (Package, error) {
+	*s.calls++
+	return Package{}, s.err
+}
+
+func TestFallbackPolicy(t *testing.T) {
+	var first, second int
+	s := NewFallbackSearcher(nil,
+		errSearcher{InvalidStatusError(404), &first},
+		errSearcher{InvalidStatusError(500), &second},
+	)
+
+	_, err := s.Search(context.Background(), "pkg")
+	var fallbackErr *FallbackError
+	if !errors.As(err, &fallbackErr) || len(fallbackErr.Errors) != 2 {
+		t.Fatalf("expected fallback error with 2 errors, got %v", err)
+	}
+	if !errors.Is(err, InvalidStatusError(404)) {
+		t.Errorf("expected error to match not found, got %v", err)
+	}
+
+	first, second = 0, 0
+	s = NewFallbackSearcher(nil,
+		errSearcher{InvalidStatusError(403), &first},
+		errSearcher{nil, &second},
+	)
+	_, err = s.Search(context.Background(), "pkg")
+	if !errors.Is(err, InvalidStatusError(403)) {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+	if first != 1 || second != 0 {
+		t.Errorf("expected only the first searcher to be called, got %d and %d calls", first, second)
+	}
+}
+
+func TestFallbackEmpty(t *testing.T) {
+	s := NewFallbackSearcher(nil)
+	if _, err := s.Search(context.Background(), "pkg"); !errors.Is(err, ErrNoSearchers) {
+		t.Errorf("expected ErrNoSearchers, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package doc
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const fileStoreExt = ".gob.gz"
+
+// fileStore is a Store that keeps each package in its own gzip compressed gob
+// file. The modification time of each file is used as the Updated time of the
+// package, so that touching an entry does not require rewriting it.
+type fileStore struct {
+	dir string
+}
+
+// fileHeader is written before the package in each file, so that the entries
+// of the store can be listed without decoding every package.
+type fileHeader struct {
+	Key          string
+	Created      time.Time
+	ETag         string
+	LastModified string
+}
+
+// fileStore implements the Store interface.
+var _ Store = fileStore{}
+
+// NewFileStore creates a Store that persists packages in dir, which is created
+// if it does not exist.
+//
+// Files are replaced atomically, so a store may be shared by multiple
+// processes. When two processes write the same package, the last write wins.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return fileStore{dir: dir}, nil
+}
+
+// path returns the file name for a key. Keys are hashed as module paths may
+// contain characters that are not allowed in file names, and may only differ
+// in case.
+func (f fileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+fileStoreExt)
+}
+
+func (f fileStore) Get(key string) (*CachedPackage, bool, error) {
+	file, err := os.Open(f.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, false, err
+	}
+	dec := gob.NewDecoder(zr)
+
+	var header fileHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, false, err
+	}
+	// guard against the unlikely case of a hash collision.
+	if header.Key != key {
+		return nil, false, nil
+	}
+
+	cPkg := &CachedPackage{
+		Created:      header.Created,
+		Updated:      info.ModTime(),
+		ETag:         header.ETag,
+		LastModified: header.LastModified,
+	}
+	if err := dec.Decode(&cPkg.Package); err != nil {
+		return nil, false, err
+	}
+	return cPkg, true, nil
+}
+
+func (f fileStore) Put(key string, pkg *CachedPackage) error {
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	// the rename below makes this a no-op on success.
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	enc := gob.NewEncoder(zw)
+	err = enc.Encode(fileHeader{
+		Key:          key,
+		Created:      pkg.Created,
+		ETag:         pkg.ETag,
+		LastModified: pkg.LastModified,
+	})
+	if err == nil {
+		err = enc.Encode(pkg.Package)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(tmp.Name(), pkg.Updated, pkg.Updated); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(key))
+}
+
+func (f fileStore) Delete(key string) error {
+	err := os.Remove(f.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f fileStore) Touch(key string, updated time.Time) error {
+	err := os.Chtimes(f.path(key), updated, updated)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Entries lists the packages in the store. Files that can not be read, such as
+// ones removed by another process in the meantime, are skipped.
+func (f fileStore) Entries() ([]StoreEntry, error) {
+	dirEntries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StoreEntry, 0, len(dirEntries))
+	for _, e := range dirEntries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileStoreExt) {
+			continue
+		}
+		entry, err := f.entry(filepath.Join(f.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Size returns the number of bytes used by the compressed files on disk.
+func (f fileStore) Size() (int64, error) {
+	dirEntries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, e := range dirEntries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileStoreExt) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			n += info.Size()
+		}
+	}
+	return n, nil
+}
+
+func (f fileStore) entry(path string) (StoreEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return StoreEntry{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return StoreEntry{}, err
+	}
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return StoreEntry{}, err
+	}
+
+	var header fileHeader
+	if err := gob.NewDecoder(zr).Decode(&header); err != nil {
+		return StoreEntry{}, err
+	}
+	return StoreEntry{
+		Key:     header.Key,
+		Created: header.Created,
+		Updated: info.ModTime(),
+	}, nil
+}
@@ -0,0 +1,130 @@
+package doc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create store: %v", err)
+	}
+
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	cPkg := &CachedPackage{
+		Package: Package{
+			Name:     "bytes",
+			Overview: Comment{Heading("Usage"), Paragraph("Package bytes."), Pre("bytes.Equal(a, b)\n")},
+			Functions: map[string]Function{
+				"equal": {Name: "Equal", Signature: "func Equal(a, b []byte) bool"},
+			},
+		},
+		Created: created,
+		Updated: created,
+	}
+	if err := store.Put("bytes", cPkg); err != nil {
+		t.Fatalf("could not put package: %v", err)
+	}
+
+	got, ok, err := store.Get("bytes")
+	if err != nil || !ok {
+		t.Fatalf("could not get package: %v", err)
+	}
+	if !reflect.DeepEqual(got.Package, cPkg.Package) {
+		t.Errorf("package did not round trip:\ngot  %+v\nwant %+v", got.Package, cPkg.Package)
+	}
+	if !got.Created.Equal(created) || !got.Updated.Equal(created) {
+		t.Errorf("unexpected timestamps: %v %v", got.Created, got.Updated)
+	}
+
+	updated := time.Now().Truncate(time.Second)
+	if err := store.Touch("bytes", updated); err != nil {
+		t.Fatalf("could not touch package: %v", err)
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("could not list entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Key != "bytes" || !entries[0].Updated.Equal(updated) {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	if err := store.Delete("bytes"); err != nil {
+		t.Fatalf("could not delete package: %v", err)
+	}
+	if _, ok, _ := store.Get("bytes"); ok {
+		t.Errorf("expected package to be deleted")
+	}
+}
+
+func TestCacheFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := &countingSearcher{}
+
+	for range 2 {
+		store, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatalf("could not create store: %v", err)
+		}
+		c := newCachedSearcher(s, cacheConfig{store: store})
+		if _, err := c.Search(ctx, "bytes"); err != nil {
+			t.Fatalf("could not search: %v", err)
+		}
+		c.Close()
+	}
+
+	if n := s.calls.Load(); n != 1 {
+		t.Errorf("expected the second cache to use the stored package, got %d searches", n)
+	}
+}
+
+// putCountingStore counts the calls to Put of a Store.
+type putCountingStore struct {
+	Store
+	puts int
+}
+
+func (s *putCountingStore) Put(key string, pkg *CachedPackage) error {
+	s.puts++
+	return s.Store.Put(key, pkg)
+}
+
+func TestCacheFileStoreWithCache(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create store: %v", err)
+	}
+	store := &putCountingStore{Store: fileStore}
+	c := newCachedSearcher(&countingSearcher{}, cacheConfig{store: store})
+	defer c.Close()
+
+	c.Search(ctx, "a")
+	c.Search(ctx, "b")
+	store.puts = 0
+
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		if len(cache) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(cache))
+		}
+	})
+	if store.puts != 0 {
+		t.Errorf("expected reading the cache to not write, got %d writes", store.puts)
+	}
+
+	c.WithCache(func(cache map[string]*CachedPackage) {
+		cache["a"].Updated = cache["a"].Updated.Add(time.Hour)
+		cache["c"] = &CachedPackage{Package: Package{Name: "c"}, Created: time.Now(), Updated: time.Now()}
+		delete(cache, "b")
+	})
+	if store.puts != 2 {
+		t.Errorf("expected the changed and added entries to be written, got %d writes", store.puts)
+	}
+	if entries := c.Entries(); len(entries) != 2 || entries[0].Key != "a" || entries[1].Key != "c" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
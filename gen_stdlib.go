@@ -0,0 +1,53 @@
+//go:build ignore
+
+// gen_stdlib generates stdlib.txt, the index of standard library import paths
+// embedded into the doc package. It uses the go command found in PATH, so the
+// index matches the Go version the generator is run with.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func main() {
+	out, err := exec.Command("go", "list", "std").Output()
+	if err != nil {
+		log.Fatalf("could not list standard library: %v", err)
+	}
+	version, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		log.Fatalf("could not get go version: %v", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Code generated by gen_stdlib.go from %s. DO NOT EDIT.\n", bytes.TrimSpace(version))
+	for _, pkg := range strings.Fields(string(out)) {
+		if isInternal(pkg) {
+			continue
+		}
+		fmt.Fprintln(&buf, pkg)
+	}
+
+	if err := os.WriteFile("stdlib.txt", buf.Bytes(), 0o644); err != nil {
+		log.Fatalf("could not write index: %v", err)
+	}
+}
+
+// isInternal reports whether pkg cannot be imported from outside of the
+// standard library.
+func isInternal(pkg string) bool {
+	if strings.HasPrefix(pkg, "vendor/") {
+		return true
+	}
+	for _, elem := range strings.Split(pkg, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
+}
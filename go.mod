@@ -10,6 +10,9 @@ require (
 	github.com/charmbracelet/lipgloss v0.11.0
 	github.com/charmbracelet/x/term v0.1.1
 	github.com/lithammer/fuzzysearch v1.1.8
+	golang.org/x/mod v0.14.0
+	golang.org/x/net v0.25.0
+	golang.org/x/sync v0.7.0
 )
 
 require (
@@ -38,8 +41,6 @@ require (
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	github.com/yuin/goldmark v1.5.4 // indirect
 	github.com/yuin/goldmark-emoji v1.0.2 // indirect
-	golang.org/x/net v0.25.0 // indirect
-	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
 )
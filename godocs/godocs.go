@@ -1,6 +1,8 @@
 package godocs
 
 import (
+	"fmt"
+	"runtime/debug"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -20,6 +22,9 @@ type godocParser struct{}
 
 // Parser is an implementation of godoc.Parser that retrieves documentation
 // from https://godocs.io.
+//
+// godocs.io only serves the latest version of each module, so Parser does not
+// implement doc.VersionedParser.
 var Parser doc.Parser = godocParser{}
 
 // URL returns a url to the path to see the documentation for the provided
@@ -28,13 +33,26 @@ func (godocParser) URL(module string) string {
 	return base + module
 }
 
-func (p godocParser) Parse(document *goquery.Document, useCase bool) (doc.Package, error) {
+// Parse parses the documentation page of a package on godocs.io. Unexpected
+// markup that would cause a panic results in a *ParseError wrapping a
+// *doc.PanicError instead.
+func (p godocParser) Parse(document *goquery.Document, useCase, dupeTypeFuncs bool) (pkg doc.Package, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pkg, err = doc.Package{}, &ParseError{
+				Parser:  "godocs",
+				Message: "unexpected markup",
+				Err:     &doc.PanicError{Value: r, Stack: debug.Stack()},
+			}
+		}
+	}()
+
 	// special case not found case for godocs
 	if document.Find("head title").Text() == "Not Found - godocs.io" {
-		return doc.Package{}, doc.InvalidStatusError(404)
+		return doc.Package{}, fmt.Errorf("%w: not found page on godocs.io", doc.ErrNotFound)
 	}
 
-	s, err := newState(document, useCase)
+	s, err := newState(document, useCase, dupeTypeFuncs)
 	if err != nil {
 		return doc.Package{}, err
 	}
@@ -3,6 +3,7 @@ package godocs_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"testing"
 
@@ -24,3 +25,15 @@ func TestGodocs(t *testing.T) {
 	e.SetIndent("", "\t")
 	e.Encode(pkg)
 }
+
+func TestGodocsVersion(t *testing.T) {
+	if _, ok := godocs.Parser.(doc.VersionedParser); ok {
+		t.Fatalf("expected godocs parser to not support versions")
+	}
+
+	// the version is rejected before any request is made.
+	s := doc.NewSearcher(godocs.Parser)
+	if _, err := s.Search(context.Background(), "net@v1.0.0"); !errors.Is(err, doc.ErrVersionUnsupported) {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
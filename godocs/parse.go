@@ -1,61 +1,86 @@
 package godocs
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/hhhapz/doc"
 )
 
-type ParseError struct {
-	Sel     *goquery.Selection
-	Message string
-}
-
-func (err ParseError) Error() string {
-	return err.Message
-}
+// ParseError is the error returned when the page could not be parsed. It is
+// an alias of doc.ParseError, which is shared by all parsers.
+type ParseError = doc.ParseError
 
 type state struct {
 	doc     *goquery.Document
 	pkg     doc.Package
 	current *doc.Type
 	useCase bool
+	// page is the url of the page, used to resolve links.
+	page string
 }
 
 func newState(document *goquery.Document, useCase, _ bool) (*state, error) {
+	s := &state{
+		doc:     document,
+		useCase: useCase,
+	}
+
 	name := document.Find("#pkg-overview").Text()
 	name = strings.TrimPrefix(name, "package ")
 
 	sel := document.Find("#pkg-overview").NextUntil("#pkg-index")
-	// ignore first import "pkgname" p tag
-	overview := comments(sel)
+	code := sel.Find("code").First()
+	if len(code.Text()) == 0 {
+		return nil, fmt.Errorf("%w: no import path on godocs.io", doc.ErrNotFound)
+	}
+	// typically import "path"
+	url, ok := strings.CutPrefix(code.Text(), "import ")
+	if !ok || len(url) < 2 || url[0] != '"' || url[len(url)-1] != '"' {
+		return nil, &ParseError{
+			Parser:   "godocs",
+			Selector: "#pkg-overview code",
+			Sel:      code,
+			Message:  "could not get import path",
+		}
+	}
+	url = url[1 : len(url)-1]
+	s.page = base + url
+
+	overview := s.comments(sel)
 	examples := examples(sel)
-	url := sel.Find("code").First().Text()
-	if len(url) == 0 {
-		return nil, doc.InvalidStatusError(404)
+	// ignore first import "pkgname" p tag
+	if len(overview) > 0 {
+		overview = overview[1:]
+	}
+
+	if document.Find("#pkg-index").Length() == 0 {
+		s.pkg.Diagnose(doc.MissingSection, "#pkg-index", "no index")
 	}
-	url = url[8 : len(url)-1]
 
 	subpkgs := subpackages(document)
 
-	return &state{
-		doc: document,
-		pkg: doc.Package{
-			URL:         url,
-			Name:        name,
-			Overview:    overview[1:],
-			Examples:    examples,
-			Functions:   map[string]doc.Function{},
-			Types:       map[string]doc.Type{},
-			Subpackages: subpkgs,
-		},
-		useCase: useCase,
-	}, nil
+	s.pkg = doc.Package{
+		URL:         url,
+		Name:        name,
+		Overview:    overview,
+		Examples:    examples,
+		Functions:   map[string]doc.Function{},
+		Types:       map[string]doc.Type{},
+		Subpackages: subpkgs,
+		Diagnostics: s.pkg.Diagnostics,
+	}
+	return s, nil
 }
 
-func (s *state) newError(sel *goquery.Selection, msg string) error {
-	return ParseError{sel, msg}
+func (s *state) newError(sel *goquery.Selection, selector, msg string) error {
+	return &ParseError{
+		Parser:   "godocs",
+		Selector: selector,
+		Sel:      sel,
+		Message:  msg,
+	}
 }
 
 func (s *state) function(sel *goquery.Selection) error {
@@ -63,16 +88,20 @@ func (s *state) function(sel *goquery.Selection) error {
 
 	name, ok := sel.Attr("id")
 	if !ok {
-		return s.newError(sel, "could not get id")
+		return s.newError(sel, `[data-kind="function"]`, "could not get id")
 	}
 	signature := next.First().Text()
+	if strings.TrimSpace(strings.TrimPrefix(signature, "❖")) == "" {
+		s.pkg.Diagnose(doc.EmptySignature, "#"+name, "no signature")
+	}
 
 	f := doc.Function{
 		Name:      name,
 		Signature: strings.TrimSpace(strings.TrimPrefix(signature, "❖")),
-		Comment:   comments(next),
+		Comment:   s.comments(next),
 		Examples:  examples(next),
 	}
+	s.pkg.ParseFunction(&f, "#"+name)
 
 	if !s.useCase {
 		name = strings.ToLower(name)
@@ -97,18 +126,22 @@ func (s *state) typ(sel *goquery.Selection) error {
 	next := sel.NextUntil(selectors)
 	name, ok := sel.Attr("id")
 	if !ok {
-		return s.newError(sel, "could not get id")
+		return s.newError(sel, `[data-kind="type"]`, "could not get id")
 	}
 	signature := next.First().Text()
+	if strings.TrimSpace(strings.TrimPrefix(signature, "❖")) == "" {
+		s.pkg.Diagnose(doc.EmptySignature, "#"+name, "no signature")
+	}
 
 	t := doc.Type{
 		Name:          name,
 		Signature:     strings.TrimSpace(strings.TrimPrefix(signature, "❖")),
-		Comment:       comments(next),
+		Comment:       s.comments(next),
 		Examples:      examples(next),
 		TypeFunctions: map[string]doc.Function{},
 		Methods:       map[string]doc.Method{},
 	}
+	s.pkg.ParseType(&t, "#"+name)
 
 	s.current = &t
 	return nil
@@ -116,28 +149,32 @@ func (s *state) typ(sel *goquery.Selection) error {
 
 func (s *state) method(sel *goquery.Selection) error {
 	if s.current == nil {
-		return s.newError(sel, "could not get method type")
+		return s.newError(sel, `[data-kind="method"]`, "could not get method type")
 	}
 
 	next := sel.NextUntil(selectors)
 	name, ok := sel.Attr("id")
 	if !ok {
-		return s.newError(sel, "could not get id")
+		return s.newError(sel, `[data-kind="method"]`, "could not get id")
 	}
 	split := strings.SplitN(name, ".", 2)
 	name = split[len(split)-1]
 
 	signature := next.First().Text()
+	if strings.TrimSpace(strings.TrimPrefix(signature, "❖")) == "" {
+		s.pkg.Diagnose(doc.EmptySignature, "#"+split[0]+"."+name, "no signature")
+	}
 
 	m := doc.Method{
 		For: split[0],
 		Function: doc.Function{
 			Name:      name,
 			Signature: strings.TrimSpace(strings.TrimPrefix(signature, "❖")),
-			Comment:   comments(next),
+			Comment:   s.comments(next),
 			Examples:  examples(next),
 		},
 	}
+	s.pkg.ParseFunction(&m.Function, "#"+split[0]+"."+name)
 
 	if !s.useCase {
 		name = strings.ToLower(name)
@@ -165,18 +202,19 @@ func subpackages(doc *goquery.Document) []string {
 	return pkgs
 }
 
-func comments(sel *goquery.Selection) doc.Comment {
-	sel = sel.Filter("p, pre, h4")
+func (s *state) comments(sel *goquery.Selection) doc.Comment {
+	sel = sel.Filter("p, pre, h4, ul, ol")
 	comments := make(doc.Comment, 0, len(sel.Nodes))
 
-	sel.Each(func(i int, s *goquery.Selection) {
+	sel.Each(func(i int, item *goquery.Selection) {
 		node := sel.Get(i)
 		switch node.Data {
 		case "p":
-			f := strings.Fields(s.Text())
-			comments = append(comments, doc.Paragraph(strings.Join(f, " ")))
+			comments = append(comments, doc.ParseParagraph(item, s.page))
+		case "ul", "ol":
+			comments = append(comments, doc.ParseList(item, s.page))
 		case "pre":
-			comments = append(comments, doc.Pre(s.Text()))
+			comments = append(comments, doc.Pre(item.Text()))
 		case "h4":
 			var ok bool
 			for _, attr := range node.Attr {
@@ -186,9 +224,10 @@ func comments(sel *goquery.Selection) doc.Comment {
 				}
 			}
 			if !ok {
+				s.pkg.Diagnose(doc.UnrecognizedNode, "h4", "skipped heading without id: "+strings.TrimSpace(item.Text()))
 				return
 			}
-			text := strings.TrimSpace(s.Text())
+			text := strings.TrimSpace(item.Text())
 			comments = append(comments, doc.Heading(text))
 		}
 	})
@@ -0,0 +1,213 @@
+package godocs_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/hhhapz/doc"
+	"github.com/hhhapz/doc/godocs"
+)
+
+func parseFile(t *testing.T, name string) (doc.Package, error) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("could not read fixture: %v", err)
+	}
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("could not parse html: %v", err)
+	}
+	return godocs.Parser.Parse(document, false, false)
+}
+
+func TestParse(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	if pkg.URL != "example.com/pkg" || pkg.Name != "pkg" {
+		t.Errorf("expected example.com/pkg named pkg, got %q named %q", pkg.URL, pkg.Name)
+	}
+	if len(pkg.Overview) == 0 || pkg.Overview[0] != doc.Paragraph("Package pkg does things.") {
+		t.Errorf("unexpected overview: %#v", pkg.Overview)
+	}
+	rich := doc.RichText{
+		doc.Plain("See "),
+		doc.DocLink{Label: "http.Server", ImportPath: "net/http", Name: "Server", URL: "https://godocs.io/net/http#Server"},
+		doc.Plain(", "),
+		doc.DocLink{Label: "T.Run", Recv: "T", Name: "Run", URL: "https://godocs.io/example.com/pkg#T.Run"},
+		doc.Plain(" and "),
+		doc.Code("Do"),
+		doc.Plain(", or "),
+		doc.Link{Label: "the Go website", URL: "https://go.dev/"},
+		doc.Plain(". Also "),
+		doc.DocLink{Label: "http.Client", ImportPath: "net/http", Name: "Client", URL: "https://godocs.io/net/http#Client"},
+		doc.Plain(", "),
+		doc.Link{Label: "Usage", URL: "https://godocs.io/example.com/pkg#hdr-Usage"},
+		doc.Plain(" and "),
+		doc.Link{Label: "about", URL: "https://godocs.io/about"},
+		doc.Plain("."),
+	}
+	if len(pkg.Overview) < 3 || !reflect.DeepEqual(pkg.Overview[1], rich) {
+		t.Errorf("unexpected rich text: %#v", pkg.Overview)
+	}
+	list := doc.List{Ordered: true, Items: []doc.ListItem{
+		{Number: "1", Content: doc.Comment{doc.Paragraph("First.")}},
+		{Number: "2", Content: doc.Comment{doc.Paragraph("Second.")}},
+	}}
+	if len(pkg.Overview) < 3 || !reflect.DeepEqual(pkg.Overview[2], list) {
+		t.Errorf("unexpected list: %#v", pkg.Overview)
+	}
+	if len(pkg.Examples) != 1 || pkg.Examples[0].Output != "done" {
+		t.Errorf("unexpected examples: %#v", pkg.Examples)
+	}
+	if _, ok := pkg.Functions["do"]; !ok {
+		t.Errorf("expected function Do, got %v", pkg.Functions)
+	}
+	typ, ok := pkg.Types["t"]
+	if !ok {
+		t.Fatalf("expected type T, got %v", pkg.Types)
+	}
+	if _, ok := typ.TypeFunctions["newt"]; !ok {
+		t.Errorf("expected type function NewT, got %v", typ.TypeFunctions)
+	}
+	if m, ok := typ.Methods["run"]; !ok || m.For != "T" {
+		t.Errorf("expected method Run on T, got %v", typ.Methods)
+	}
+	if len(pkg.Subpackages) != 1 || pkg.Subpackages[0] != "example.com/pkg/sub" {
+		t.Errorf("unexpected subpackages: %v", pkg.Subpackages)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	fields := []doc.Field{
+		{Name: "Name", Type: "string", Tag: `json:"name"`, Comment: doc.Comment{doc.Paragraph("Name is the name.")}},
+		{Name: "Buffer", Type: "*bytes.Buffer", Embedded: true},
+		{Name: "X", Type: "int", Comment: doc.Comment{doc.Paragraph("X and Y are coordinates.")}},
+		{Name: "Y", Type: "int", Comment: doc.Comment{doc.Paragraph("X and Y are coordinates.")}},
+	}
+	if got := pkg.Types["t"].Fields; !reflect.DeepEqual(got, fields) {
+		t.Errorf("unexpected fields: %#v", got)
+	}
+
+	methods := []doc.InterfaceMethod{
+		{Name: "Run", Signature: "Run(ctx context.Context) error", Comment: doc.Comment{doc.Paragraph("Run runs until ctx is done.")}},
+		{Name: "Stringer", Signature: "fmt.Stringer", Embedded: true},
+	}
+	if got := pkg.Types["runner"].InterfaceMethods; !reflect.DeepEqual(got, methods) {
+		t.Errorf("unexpected interface methods: %#v", got)
+	}
+
+	if kind := pkg.Types["t"].Type; kind != doc.StructType {
+		t.Errorf("expected T to be a struct, got %q", kind)
+	}
+	if types := pkg.TypesOfKind(doc.InterfaceType); len(types) != 1 || types[0].Name != "Runner" {
+		t.Errorf("expected Runner to be the only interface, got %v", types)
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	want := &doc.Signature{Results: []doc.Param{{Type: "error"}}}
+	if got := pkg.Functions["do"].Parsed; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected signature for Do: %#v", got)
+	}
+	want = &doc.Signature{Receiver: &doc.Receiver{Name: "t", Type: "T"}}
+	if got := pkg.Types["t"].Methods["run"].Parsed; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected signature for T.Run: %#v", got)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+	if len(pkg.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", pkg.Diagnostics)
+	}
+
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(`<h2 id="pkg-overview">package pkg</h2><p><code>import "example.com/pkg"</code></p><h4>Heading</h4><ul><li>item</li></ul><h3 data-kind="function" id="Do">func Do</h3>`))
+	if err != nil {
+		t.Fatalf("could not parse html: %v", err)
+	}
+	pkg, err = godocs.Parser.Parse(document, false, false)
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	kinds := map[doc.DiagnosticKind]int{}
+	for _, d := range pkg.Diagnostics {
+		kinds[d.Kind]++
+	}
+	for _, kind := range []doc.DiagnosticKind{doc.MissingSection, doc.EmptySignature, doc.UnrecognizedNode} {
+		if kinds[kind] == 0 {
+			t.Errorf("expected %v diagnostic, got %v", kind, pkg.Diagnostics)
+		}
+	}
+}
+
+func TestParseNotFound(t *testing.T) {
+	if _, err := parseFile(t, "notfound.html"); !errors.Is(err, doc.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, html := range []string{
+		`<h2 id="pkg-overview">package pkg</h2><p><code>"</code></p>`,
+		`<h2 id="pkg-overview">package pkg</h2><p><code>import x</code></p>`,
+	} {
+		document, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		if err != nil {
+			t.Fatalf("could not parse html: %v", err)
+		}
+
+		_, err = godocs.Parser.Parse(document, false, false)
+		var parseErr *godocs.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("expected parse error for %q, got %v", html, err)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, name := range []string{"package.html", "notfound.html"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			f.Fatalf("could not read fixture: %v", err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte(`<h2 id="pkg-overview">package pkg</h2><p><code>"</code></p>`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		document, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		_, err = godocs.Parser.Parse(document, false, true)
+		var panicErr *doc.PanicError
+		if errors.As(err, &panicErr) {
+			t.Fatalf("parser panicked: %v\n%s", panicErr.Value, panicErr.Stack)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package doc
+
+import (
+	"context"
+	"time"
+)
+
+// RequestInfo describes a request that a searcher is about to make.
+type RequestInfo struct {
+	// Module is the module being searched for.
+	Module string
+	// URL is the url of the documentation page.
+	URL string
+	// Attempt is the number of the attempt, starting at 1. It is greater than
+	// 1 when the request is retried.
+	Attempt int
+}
+
+// ResponseInfo describes the response to a request made by a searcher.
+type ResponseInfo struct {
+	// Module is the module being searched for.
+	Module string
+	// URL is the url of the documentation page.
+	URL string
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+	// Status is the status code of the response, or 0 if no response was
+	// received.
+	Status int
+	// Bytes is the size of the body of successful responses.
+	Bytes int64
+	// Duration is the time taken to make the request and read the body.
+	Duration time.Duration
+	// Err is the error of the request, if it failed.
+	Err error
+}
+
+// ParseInfo describes the parsing of a documentation page by a searcher.
+type ParseInfo struct {
+	// Module is the module being searched for.
+	Module string
+	// URL is the url of the documentation page.
+	URL string
+	// Duration is the time taken to parse the page.
+	Duration time.Duration
+	// Err is the error returned by the parser, if parsing failed.
+	Err error
+}
+
+// hooks are the functions called by httpSearcher during a search.
+type hooks struct {
+	onRequest  []func(context.Context, RequestInfo)
+	onResponse []func(context.Context, ResponseInfo)
+	onParse    []func(context.Context, ParseInfo)
+}
+
+// OnRequest calls f before every request made by the searcher, including
+// retries. Hooks are called synchronously, and may be added more than once.
+func OnRequest(f func(ctx context.Context, info RequestInfo)) SearchOption {
+	return func(s *httpSearcher) {
+		s.hooks.onRequest = append(s.hooks.onRequest, f)
+	}
+}
+
+// OnResponse calls f after every request made by the searcher, when the body
+// of the response has been read or the request has failed.
+func OnResponse(f func(ctx context.Context, info ResponseInfo)) SearchOption {
+	return func(s *httpSearcher) {
+		s.hooks.onResponse = append(s.hooks.onResponse, f)
+	}
+}
+
+// OnParse calls f after every documentation page parsed by the searcher.
+func OnParse(f func(ctx context.Context, info ParseInfo)) SearchOption {
+	return func(s *httpSearcher) {
+		s.hooks.onParse = append(s.hooks.onParse, f)
+	}
+}
+
+func (h hooks) request(ctx context.Context, info RequestInfo) {
+	for _, f := range h.onRequest {
+		f(ctx, info)
+	}
+}
+
+func (h hooks) response(ctx context.Context, info ResponseInfo) {
+	for _, f := range h.onResponse {
+		f(ctx, info)
+	}
+}
+
+func (h hooks) parse(ctx context.Context, info ParseInfo) {
+	for _, f := range h.onParse {
+		f(ctx, info)
+	}
+}
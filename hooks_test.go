@@ -0,0 +1,130 @@
+package doc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "<html><head><title>pkg</title></head></html>")
+	}))
+	defer srv.Close()
+
+	var reqs []RequestInfo
+	var resps []ResponseInfo
+	var parses []ParseInfo
+	s := NewSearcher(stubParser{srv.URL},
+		WithClient(srv.Client()),
+		WithRetry(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		OnRequest(func(_ context.Context, info RequestInfo) { reqs = append(reqs, info) }),
+		OnResponse(func(_ context.Context, info ResponseInfo) { resps = append(resps, info) }),
+		OnParse(func(_ context.Context, info ParseInfo) { parses = append(parses, info) }),
+	)
+	if _, err := s.Search(context.Background(), "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+
+	if len(reqs) != 2 || reqs[0].Attempt != 1 || reqs[1].Attempt != 2 {
+		t.Errorf("expected 2 request attempts, got %+v", reqs)
+	}
+	if len(resps) != 2 {
+		t.Fatalf("expected 2 responses, got %+v", resps)
+	}
+	if resps[0].Status != 503 || resps[0].Err == nil {
+		t.Errorf("expected first response to fail with 503, got %+v", resps[0])
+	}
+	if resps[1].Status != 200 || resps[1].Bytes == 0 || resps[1].Module != "pkg" {
+		t.Errorf("expected successful second response with a body, got %+v", resps[1])
+	}
+	if len(parses) != 1 || parses[0].Err != nil || parses[0].URL != srv.URL+"/pkg" {
+		t.Errorf("expected 1 successful parse, got %+v", parses)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<html><head><title>pkg</title></head></html>")
+	}))
+	defer srv.Close()
+
+	m := NewMetrics()
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithMetrics(m))
+	s.Search(context.Background(), "pkg")
+	s.Search(context.Background(), "missing")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+
+	u, _ := url.Parse(srv.URL)
+	for _, want := range []string{
+		"# TYPE doc_requests_total counter",
+		fmt.Sprintf("doc_requests_total{host=%q,status=\"200\"} 1", u.Host),
+		fmt.Sprintf("doc_requests_total{host=%q,status=\"404\"} 1", u.Host),
+		fmt.Sprintf("doc_request_duration_seconds_count{host=%q} 2", u.Host),
+		fmt.Sprintf("doc_parse_duration_seconds_count{host=%q} 1", u.Host),
+		fmt.Sprintf("doc_parse_errors_total{host=%q} 0", u.Host),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, out)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+}
+
+// recordingWriter records a response in m while it is written to.
+type recordingWriter struct {
+	b strings.Builder
+	m *Metrics
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.m.response(context.Background(), ResponseInfo{URL: "https://example.com/pkg", Status: 200})
+	return w.b.Write(p)
+}
+
+func TestMetricsWriteToUnlocked(t *testing.T) {
+	m := NewMetrics()
+	w := &recordingWriter{m: m}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.WriteTo(w)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected metrics to be recorded while being written")
+	}
+
+	if strings.Contains(w.b.String(), "example.com") {
+		t.Errorf("expected a snapshot from before the write, got:\n%s", w.b.String())
+	}
+	var b strings.Builder
+	m.WriteTo(&b)
+	if want := `doc_requests_total{host="example.com",status="200"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, b.String())
+	}
+}
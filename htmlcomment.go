@@ -0,0 +1,214 @@
+package doc
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ParseParagraph converts a <p> element of a rendered documentation page into
+// a note, for use by parsers. Paragraphs containing links or code spans are
+// converted into RichText, other paragraphs into a Paragraph. Whitespace is
+// collapsed in both cases.
+//
+// Links are resolved against page, the url of the documentation page. Links to
+// packages and symbols documented on the same site become a DocLink, while
+// links to headings and other pages stay a Link.
+func ParseParagraph(sel *goquery.Selection, page string) Note {
+	var nodes []*html.Node
+	for _, n := range sel.Nodes {
+		nodes = append(nodes, children(n)...)
+	}
+	return paragraph(nodes, parsePage(page))
+}
+
+// ParseList converts a <ul> or <ol> element of a rendered documentation page
+// into a List, for use by parsers. Links are resolved like in ParseParagraph.
+func ParseList(sel *goquery.Selection, page string) List {
+	if len(sel.Nodes) == 0 {
+		return List{}
+	}
+	return list(sel.Nodes[0], parsePage(page))
+}
+
+func parsePage(page string) *url.URL {
+	u, err := url.Parse(page)
+	if err != nil {
+		return &url.URL{}
+	}
+	return u
+}
+
+func list(n *html.Node, page *url.URL) List {
+	l := List{Ordered: n.Data == "ol"}
+
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			continue
+		}
+		if value, err := strconv.Atoi(attr(c, "value")); err == nil {
+			number = value
+		}
+
+		item := ListItem{Content: blocks(c, page)}
+		if l.Ordered {
+			item.Number = strconv.Itoa(number)
+		}
+		number++
+		l.Items = append(l.Items, item)
+	}
+	return l
+}
+
+// blocks converts the children of n into notes. Consecutive inline nodes are
+// grouped into paragraphs.
+func blocks(n *html.Node, page *url.URL) Comment {
+	var notes Comment
+	var inline []*html.Node
+	flush := func() {
+		if p := paragraph(inline, page); p.Text() != "" {
+			notes = append(notes, p)
+		}
+		inline = nil
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			inline = append(inline, c)
+			continue
+		}
+
+		switch c.Data {
+		case "p":
+			flush()
+			notes = append(notes, paragraph(children(c), page))
+		case "ul", "ol":
+			flush()
+			notes = append(notes, list(c, page))
+		case "pre":
+			flush()
+			notes = append(notes, Pre(text(c)))
+		default:
+			inline = append(inline, c)
+		}
+	}
+	flush()
+	return notes
+}
+
+func children(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		nodes = append(nodes, c)
+	}
+	return nodes
+}
+
+// paragraph converts inline nodes into a paragraph using NewParagraph.
+func paragraph(nodes []*html.Node, page *url.URL) Note {
+	var notes []Note
+	for _, n := range nodes {
+		notes = appendInline(notes, n, page)
+	}
+	return NewParagraph(notes...)
+}
+
+func appendInline(notes []Note, n *html.Node, page *url.URL) []Note {
+	switch n.Type {
+	case html.TextNode:
+		return append(notes, Plain(n.Data))
+	case html.ElementNode:
+	default:
+		return notes
+	}
+
+	switch n.Data {
+	case "code":
+		return append(notes, Code(text(n)))
+	case "a":
+		if href := attr(n, "href"); href != "" {
+			return append(notes, link(collapse(strings.TrimSpace(text(n))), href, page))
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		notes = appendInline(notes, c, page)
+	}
+	return notes
+}
+
+// link converts a hyperlink into a Link, or a DocLink if it points to a
+// package or symbol documented on the same site as the page. Links to other
+// parts of a page, such as headings, and to other pages of the site stay a
+// Link.
+func link(label, href string, page *url.URL) Note {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return Link{Label: label, URL: href}
+	}
+	abs := page.ResolveReference(ref)
+
+	l := Link{Label: label, URL: abs.String()}
+	if abs.Host != page.Host || abs.RawQuery != "" || !isSymbol(abs.Fragment) {
+		return l
+	}
+
+	d := DocLink{Label: label, URL: abs.String()}
+	if ref.Path != "" || ref.Host != "" {
+		path, _ := SplitVersion(strings.TrimPrefix(abs.Path, "/"))
+		if !isPackagePath(path) {
+			return l
+		}
+		d.ImportPath = path
+	}
+	d.Name = abs.Fragment
+	if recv, name, ok := strings.Cut(abs.Fragment, "."); ok {
+		d.Recv, d.Name = recv, name
+	}
+	return d
+}
+
+// isSymbol reports whether the fragment of a link is empty or the name of a
+// symbol, such as "Client" or "Client.Do", rather than an anchor like
+// "hdr-Overview" or "pkg-constants".
+func isSymbol(fragment string) bool {
+	return !strings.ContainsAny(fragment, "-/ ")
+}
+
+// isPackagePath reports whether path looks like an import path, either of a
+// standard library package or starting with a domain, as opposed to a page
+// such as "about".
+func isPackagePath(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return strings.Contains(first, ".") || IsStdlib(path)
+}
+
+func text(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -1,10 +1,14 @@
 package doc
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -15,15 +19,6 @@ type Parser interface {
 	Parse(document *goquery.Document, useCase, dupeTypeFuncs bool) (Package, error)
 }
 
-// InvalidStatusError indicates that the request to the godocs.io was not
-// successful. The value is the status that was returned from the page instead.
-type InvalidStatusError int
-
-// Error satisfies the error interface.
-func (err InvalidStatusError) Error() string {
-	return fmt.Sprintf("invalid response status: %d", err)
-}
-
 // httpSearcher provides an interface to search the godocs package module page.
 // It implements the Searcher interface. A parser must be provided, such as
 // pkgsite.Parser, or godoc.Parser.
@@ -38,6 +33,14 @@ type httpSearcher struct {
 	agent              string
 	withCase           bool
 	duplicateTypeFuncs bool
+	expandShortNames   bool
+	retry              RetryPolicy
+	limiter            *RateLimiter
+	hooks              hooks
+	strict             bool
+
+	// cache is only used by NewCachedSearcher.
+	cache cacheConfig
 }
 
 // httpSearcher implements the Searcher interface.
@@ -50,23 +53,140 @@ var _ Searcher = httpSearcher{}
 // parse.go
 //
 // If the page does not respond with a 200 status code, a InvalidStatusError is
-// returned. If the page could not be parsed by GoQuery, the error will be of
-// type Otherwise, issues while parsing the document will of type ParseError,
-// and will contain the selector being parsed, for more context.
+// returned, which matches ErrNotFound, ErrRateLimited or ErrUpstream depending
+// on the status. Not found pages served with a 200 status code also result in
+// an error matching ErrNotFound. Issues while parsing the document are of type
+// *ParseError, and will contain the module and selector being parsed, for more
+// context.
+//
+// If the module could not be found and there are standard library packages
+// with a similar path, the error is wrapped in a *SuggestionError.
+//
+// The module may be suffixed with @version to search for a specific version of
+// the module. If a version is requested and the parser does not implement
+// VersionedParser, ErrVersionUnsupported is returned.
 func (h httpSearcher) Search(ctx context.Context, module string) (Package, error) {
-	body, err := h.request(ctx, module)
+	pkg, _, err := h.searchConditional(ctx, module, validators{})
+	return pkg, err
+}
+
+// validators are the response headers used to make conditional requests.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// sent reports whether any validators are sent with a request, making it
+// conditional.
+func (v validators) sent() bool {
+	return v.etag != "" || v.lastModified != ""
+}
+
+// errNotModified is returned by searchConditional when the page has not
+// changed since the validators were received.
+var errNotModified = errors.New("not modified")
+
+// conditionalSearcher is implemented by searchers that can revalidate a
+// previous result, such as httpSearcher.
+type conditionalSearcher interface {
+	// searchConditional searches for the module, sending If-None-Match and
+	// If-Modified-Since headers based on v. If the page has not been modified,
+	// errNotModified is returned. Otherwise, the validators of the new
+	// response are returned with the package.
+	searchConditional(ctx context.Context, module string, v validators) (Package, validators, error)
+}
+
+func (h httpSearcher) searchConditional(ctx context.Context, module string, v validators) (Package, validators, error) {
+	if h.expandShortNames {
+		var err error
+		if module, err = expand(module); err != nil {
+			return Package{}, validators{}, err
+		}
+	}
+
+	pkg, v, err := h.search(ctx, module, v)
+	if errors.Is(err, ErrNotFound) {
+		if suggestions := Suggest(module); len(suggestions) > 0 {
+			err = &SuggestionError{Module: module, Suggestions: suggestions, Err: err}
+		}
+	}
+	return pkg, v, err
+}
+
+func (h httpSearcher) search(ctx context.Context, module string, v validators) (Package, validators, error) {
+	url, err := h.url(module)
 	if err != nil {
-		return Package{}, err
+		return Package{}, validators{}, err
 	}
-	defer body.Close()
 
-	document, err := goquery.NewDocumentFromReader(body)
+	body, v, err := h.request(ctx, module, url, v)
+	if err != nil {
+		return Package{}, validators{}, err
+	}
+
+	start := time.Now()
+	pkg, err := h.parse(body)
+	h.hooks.parse(ctx, ParseInfo{
+		Module:   module,
+		URL:      url,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) && parseErr.Module == "" {
+			parseErr.Module = module
+		}
+		return Package{}, validators{}, err
+	}
+	if h.strict && len(pkg.Diagnostics) > 0 {
+		return Package{}, validators{}, &DiagnosticsError{Module: module, Diagnostics: pkg.Diagnostics}
+	}
+	pkg.Source = source(url)
+	return pkg, v, nil
+}
+
+func (h httpSearcher) parse(body []byte) (Package, error) {
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return Package{}, err
 	}
 	return h.parser.Parse(document, h.withCase, h.duplicateTypeFuncs)
 }
 
+// expand replaces a short standard library package name in module with its
+// full import path.
+func expand(module string) (string, error) {
+	path, version := SplitVersion(module)
+	if strings.ContainsAny(path, "./") {
+		return module, nil
+	}
+
+	switch candidates := ExpandShortName(path); len(candidates) {
+	case 0:
+		return module, nil
+	case 1:
+		return JoinVersion(candidates[0], version), nil
+	default:
+		return "", &AmbiguousPackageError{Name: path, Candidates: candidates}
+	}
+}
+
+// url returns the url of the documentation page for the module, which may
+// include a version.
+func (h httpSearcher) url(module string) (string, error) {
+	path, version := SplitVersion(module)
+	if version == "" {
+		return h.parser.URL(path), nil
+	}
+
+	p, ok := h.parser.(VersionedParser)
+	if !ok {
+		return "", ErrVersionUnsupported
+	}
+	return p.VersionURL(path, version), nil
+}
+
 func (h *httpSearcher) withAgent(agent string) {
 	h.agent = agent
 }
@@ -75,24 +195,101 @@ func (h *httpSearcher) maintainCase() {
 	h.withCase = true
 }
 
-// request is a helper function to do the http request and return the body.
-func (h httpSearcher) request(ctx context.Context, module string) (io.ReadCloser, error) {
-	url := h.parser.URL(module)
+// request is a helper function to do the http request and return the body,
+// along with the validators of the response. Failed requests are retried
+// according to the retry policy of the searcher.
+func (h httpSearcher) request(ctx context.Context, module, url string, v validators) ([]byte, validators, error) {
+	for attempt := 1; ; attempt++ {
+		body, respV, retryAfter, err := h.do(ctx, module, url, v, attempt)
+		if err == nil || errors.Is(err, errNotModified) {
+			return body, respV, err
+		}
+
+		delay, ok := h.retry.delay(attempt, err, retryAfter)
+		if !ok {
+			return nil, validators{}, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, validators{}, err
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, validators{}, err
+		}
+	}
+}
+
+// do makes a single request and reads the body of the response. If the
+// response was not successful, the delay requested by its Retry-After header
+// is returned with the error.
+func (h httpSearcher) do(ctx context.Context, module, url string, v validators, attempt int) ([]byte, validators, time.Duration, error) {
 	r, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
 	if err != nil {
-		return nil, err
+		return nil, validators{}, 0, err
+	}
+	if err := h.limiter.Wait(ctx, r.URL.Host); err != nil {
+		return nil, validators{}, 0, err
 	}
 
 	r.Header.Add("User-Agent", h.agent)
+	if v.etag != "" {
+		r.Header.Add("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		r.Header.Add("If-Modified-Since", v.lastModified)
+	}
+
+	h.hooks.request(ctx, RequestInfo{
+		Module:  module,
+		URL:     url,
+		Attempt: attempt,
+	})
+	info := ResponseInfo{
+		Module:  module,
+		URL:     url,
+		Attempt: attempt,
+	}
+	start := time.Now()
+	defer func() {
+		info.Duration = time.Since(start)
+		h.hooks.response(ctx, info)
+	}()
 
 	resp, err := h.client.Do(r)
 	if err != nil {
-		return nil, err
+		info.Err = err
+		return nil, validators{}, 0, err
 	}
+	defer resp.Body.Close()
+	info.Status = resp.StatusCode
 
-	if c := resp.StatusCode; c != 200 {
-		return nil, InvalidStatusError(c)
+	switch c := resp.StatusCode; {
+	case c == http.StatusOK:
+	case c == http.StatusNotModified && v.sent():
+		return nil, validators{}, 0, errNotModified
+	default:
+		info.Err = InvalidStatusError(c)
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, validators{}, retryAfter, info.Err
 	}
 
-	return resp.Body, nil
+	body, err := io.ReadAll(resp.Body)
+	info.Bytes = int64(len(body))
+	if err != nil {
+		info.Err = err
+		return nil, validators{}, 0, err
+	}
+
+	return body, validators{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, 0, nil
+}
+
+// source returns the host of the url a package was retrieved from.
+func source(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
 }
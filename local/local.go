@@ -0,0 +1,301 @@
+// Package local implements a doc.Searcher that reads documentation straight
+// from Go source files on disk, without making any network requests.
+//
+// Import paths are resolved against GOROOT, the module containing the working
+// directory, and the module cache, in that order.
+package local
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/build"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hhhapz/doc"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Option configures the searcher returned by NewSearcher.
+type Option func(s *searcher)
+
+// searcher provides documentation for packages found on the local file
+// system. It implements the doc.Searcher interface.
+type searcher struct {
+	goroot   string
+	modcache string
+	dir      string
+
+	useCase            bool
+	duplicateTypeFuncs bool
+}
+
+// searcher implements the doc.Searcher interface.
+var _ doc.Searcher = (*searcher)(nil)
+
+// NewSearcher creates a doc.Searcher that parses packages from local source.
+//
+// By default GOROOT and GOMODCACHE are taken from the environment, falling back
+// to the values reported by the go command, and the current module is located
+// from the working directory.
+func NewSearcher(opts ...Option) doc.Searcher {
+	s := &searcher{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.goroot == "" {
+		s.goroot = goEnv("GOROOT", build.Default.GOROOT)
+	}
+	if s.modcache == "" {
+		s.modcache = goEnv("GOMODCACHE", "")
+		if s.modcache == "" {
+			if paths := filepath.SplitList(build.Default.GOPATH); len(paths) > 0 {
+				s.modcache = filepath.Join(paths[0], "pkg", "mod")
+			}
+		}
+	}
+	if s.dir == "" {
+		s.dir, _ = os.Getwd()
+	}
+	return s
+}
+
+// WithGOROOT overrides the GOROOT used to find standard library packages.
+func WithGOROOT(dir string) Option {
+	return func(s *searcher) {
+		s.goroot = dir
+	}
+}
+
+// WithModCache overrides the module cache directory used to find third party
+// packages.
+func WithModCache(dir string) Option {
+	return func(s *searcher) {
+		s.modcache = dir
+	}
+}
+
+// WithDir sets the directory used to locate the current module. By default the
+// working directory of the process is used.
+func WithDir(dir string) Option {
+	return func(s *searcher) {
+		s.dir = dir
+	}
+}
+
+// MaintainCase keeps the original case of the keys in the maps of the returned
+// doc.Package, just like doc.MaintainCase.
+func MaintainCase() Option {
+	return func(s *searcher) {
+		s.useCase = true
+	}
+}
+
+// WithDuplicateTypeFuncs also adds functions that return a type to
+// doc.Package.Functions, just like doc.WithDuplicateTypeFuncs.
+func WithDuplicateTypeFuncs() Option {
+	return func(s *searcher) {
+		s.duplicateTypeFuncs = true
+	}
+}
+
+// Search finds the package with the provided import path on disk and parses
+// its documentation.
+//
+// A version may be requested with path@version. Packages in the module cache
+// are looked up at that exact version, while GOROOT packages only match the
+// version of the Go installation. If no version is requested, the highest
+// version in the module cache is used.
+//
+// If the package could not be found, an error matching doc.ErrNotFound is
+// returned, like the http based searchers.
+func (s *searcher) Search(ctx context.Context, module string) (doc.Package, error) {
+	if err := ctx.Err(); err != nil {
+		return doc.Package{}, err
+	}
+
+	path, version := doc.SplitVersion(module)
+	dir, version, ok := s.resolve(path, version)
+	if !ok {
+		return doc.Package{}, fmt.Errorf("%w: %s", doc.ErrNotFound, module)
+	}
+
+	pkg, err := parse(dir, path, s.useCase, s.duplicateTypeFuncs)
+	if err != nil {
+		return doc.Package{}, err
+	}
+	pkg.Version = version
+	pkg.Source = "local"
+	return pkg, nil
+}
+
+// resolve finds the directory containing the source of the package with the
+// provided import path, and the version of the module it belongs to.
+func (s *searcher) resolve(path, version string) (dir, resolved string, ok bool) {
+	if path == "" || module.CheckImportPath(path) != nil {
+		return "", "", false
+	}
+
+	if s.goroot != "" {
+		dir := filepath.Join(s.goroot, "src", filepath.FromSlash(path))
+		goVersion := gorootVersion(s.goroot)
+		if (version == "" || version == goVersion) && isPackage(dir) {
+			return dir, goVersion, true
+		}
+	}
+
+	if root, mod := findModule(s.dir); mod != "" && version == "" {
+		if rest, ok := trimModule(path, mod); ok {
+			if dir := filepath.Join(root, filepath.FromSlash(rest)); isPackage(dir) {
+				return dir, "", true
+			}
+		}
+	}
+
+	if s.modcache == "" {
+		return "", "", false
+	}
+
+	// try the longest module path first, as nested modules take precedence.
+	elems := strings.Split(path, "/")
+	for i := len(elems); i > 0; i-- {
+		mod := strings.Join(elems[:i], "/")
+		v, ok := s.version(mod, version)
+		if !ok {
+			continue
+		}
+		escaped, _ := module.EscapePath(mod)
+		escapedVersion, _ := module.EscapeVersion(v)
+		dir := filepath.Join(s.modcache, filepath.FromSlash(escaped)+"@"+escapedVersion)
+		dir = filepath.Join(dir, filepath.FromSlash(strings.Join(elems[i:], "/")))
+		if isPackage(dir) {
+			return dir, v, true
+		}
+	}
+	return "", "", false
+}
+
+// version returns the version of mod that is extracted in the module cache. If
+// want is empty, the highest version is returned.
+func (s *searcher) version(mod, want string) (string, bool) {
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return "", false
+	}
+	parent := filepath.Join(s.modcache, filepath.FromSlash(escaped))
+	prefix := filepath.Base(parent) + "@"
+
+	entries, err := os.ReadDir(filepath.Dir(parent))
+	if err != nil {
+		return "", false
+	}
+
+	var best string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		v, err := module.UnescapeVersion(strings.TrimPrefix(name, prefix))
+		if err != nil || !semver.IsValid(v) {
+			continue
+		}
+		if want != "" {
+			if v == want {
+				return v, true
+			}
+			continue
+		}
+		if best == "" || semver.Compare(v, best) > 0 {
+			best = v
+		}
+	}
+	return best, best != ""
+}
+
+// gorootVersion returns the version of the Go installation at goroot, such as
+// go1.22.3.
+func gorootVersion(goroot string) string {
+	b, err := os.ReadFile(filepath.Join(goroot, "VERSION"))
+	if err != nil {
+		return ""
+	}
+	version, _, _ := strings.Cut(string(b), "\n")
+	return strings.TrimSpace(version)
+}
+
+// findModule walks up from dir to find the nearest go.mod file, returning the
+// directory it is in and the module path it declares.
+func findModule(dir string) (root, mod string) {
+	if dir == "" {
+		return "", ""
+	}
+	for {
+		f, err := os.Open(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				line := strings.TrimSpace(sc.Text())
+				if rest, ok := strings.CutPrefix(line, "module"); ok {
+					return dir, strings.Trim(strings.TrimSpace(rest), `"`)
+				}
+			}
+			return dir, ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// trimModule returns the path of pkg relative to the module mod.
+func trimModule(pkg, mod string) (string, bool) {
+	if pkg == mod {
+		return "", true
+	}
+	rest, ok := strings.CutPrefix(pkg, mod+"/")
+	return rest, ok
+}
+
+// isPackage reports whether dir contains at least one non-test Go file.
+func isPackage(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if isSource(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSource(e os.DirEntry) bool {
+	name := e.Name()
+	return !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// goEnv reads key from the environment, asking the go command if it is not set.
+func goEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	out, err := exec.Command("go", "env", key).Output()
+	if err != nil {
+		return fallback
+	}
+	if v := strings.TrimSpace(string(out)); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,180 @@
+package local_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hhhapz/doc"
+	"github.com/hhhapz/doc/local"
+)
+
+const source = `// Package greet says hello.
+package greet
+
+// Greeting is the default greeting.
+const Greeting = "hello"
+
+// Greeter greets people.
+type Greeter struct {
+	// Name is the name of the greeter.
+	Name string
+	count int
+}
+
+// NewGreeter creates a Greeter.
+func NewGreeter(name string) *Greeter { return &Greeter{Name: name} }
+
+// Greet greets who.
+func (g *Greeter) Greet(who string) string { return Greeting + " " + who }
+
+// Hello says hello, using a [Greeter].
+//
+//  1. Create a greeter.
+//  2. Greet.
+func Hello() string { return Greeting }
+`
+
+const examples = `package greet_test
+
+import (
+	"fmt"
+
+	"example.com/greet"
+)
+
+func ExampleHello() {
+	fmt.Println(greet.Hello())
+	// Output: hello
+}
+
+func ExampleGreeter_Greet_world() {
+	fmt.Println(greet.NewGreeter("a").Greet("world"))
+	// Output: hello world
+}
+`
+
+func writePackage(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"greet.go": source, "example_test.go": examples}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	writePackage(t, filepath.Join(root, "src", "greet"))
+
+	s := local.NewSearcher(local.WithGOROOT(root), local.WithModCache(t.TempDir()), local.WithDir(root))
+	pkg, err := s.Search(ctx, "greet")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	if pkg.Name != "greet" || pkg.URL != "greet" {
+		t.Errorf("unexpected package name or url: %q %q", pkg.Name, pkg.URL)
+	}
+	if got := pkg.Overview.Text(); got != "Package greet says hello." {
+		t.Errorf("unexpected overview: %q", got)
+	}
+	if _, ok := pkg.ConstantMap["greeting"]; !ok {
+		t.Errorf("missing constant greeting")
+	}
+
+	hello, ok := pkg.Functions["hello"]
+	if !ok {
+		t.Fatalf("missing function hello")
+	}
+	if hello.Signature != "func Hello() string" {
+		t.Errorf("unexpected signature: %q", hello.Signature)
+	}
+	comment := doc.Comment{
+		doc.RichText{
+			doc.Plain("Hello says hello, using a "),
+			doc.DocLink{Label: "Greeter", Name: "Greeter"},
+			doc.Plain("."),
+		},
+		doc.List{Ordered: true, Items: []doc.ListItem{
+			{Number: "1", Content: doc.Comment{doc.Paragraph("Create a greeter.")}},
+			{Number: "2", Content: doc.Comment{doc.Paragraph("Greet.")}},
+		}},
+	}
+	if !reflect.DeepEqual(hello.Comment, comment) {
+		t.Errorf("unexpected comment: %#v", hello.Comment)
+	}
+	if len(hello.Examples) != 1 || hello.Examples[0].Output != "hello\n" {
+		t.Errorf("unexpected examples: %+v", hello.Examples)
+	}
+
+	greeter, ok := pkg.Types["greeter"]
+	if !ok {
+		t.Fatalf("missing type greeter")
+	}
+	if greeter.Type != doc.StructType {
+		t.Errorf("expected greeter to be a struct, got %q", greeter.Type)
+	}
+	fields := []doc.Field{{Name: "Name", Type: "string", Comment: doc.Comment{doc.Paragraph("Name is the name of the greeter.")}}}
+	if !reflect.DeepEqual(greeter.Fields, fields) {
+		t.Errorf("unexpected fields: %#v", greeter.Fields)
+	}
+	if _, ok := greeter.TypeFunctions["newgreeter"]; !ok {
+		t.Errorf("missing type function newgreeter")
+	}
+	greet, ok := greeter.Methods["greet"]
+	if !ok {
+		t.Fatalf("missing method greet")
+	}
+	sig := &doc.Signature{
+		Receiver: &doc.Receiver{Name: "g", Type: "Greeter", Pointer: true},
+		Params:   []doc.Param{{Name: "who", Type: "string"}},
+		Results:  []doc.Param{{Type: "string"}},
+	}
+	if !reflect.DeepEqual(greet.Parsed, sig) {
+		t.Errorf("unexpected method signature: %#v", greet.Parsed)
+	}
+	if greet.For != "Greeter" {
+		t.Errorf("unexpected method receiver: %q", greet.For)
+	}
+	if len(greet.Examples) != 1 || greet.Examples[0].Name != "Example (world)" {
+		t.Errorf("unexpected method examples: %+v", greet.Examples)
+	}
+}
+
+func TestLocalModCache(t *testing.T) {
+	ctx := context.Background()
+	cache := t.TempDir()
+	writePackage(t, filepath.Join(cache, "example.com", "!greet@v1.0.0"))
+	writePackage(t, filepath.Join(cache, "example.com", "!greet@v1.2.0", "sub"))
+
+	s := local.NewSearcher(local.WithGOROOT(t.TempDir()), local.WithModCache(cache), local.WithDir(t.TempDir()))
+	pkg, err := s.Search(ctx, "example.com/Greet/sub")
+	if err != nil {
+		t.Errorf("could not find package in module cache: %v", err)
+	}
+	if pkg.Version != "v1.2.0" {
+		t.Errorf("expected latest version v1.2.0, got %q", pkg.Version)
+	}
+
+	pkg, err = s.Search(ctx, "example.com/Greet@v1.0.0")
+	if err != nil {
+		t.Errorf("could not find versioned package in module cache: %v", err)
+	}
+	if pkg.Version != "v1.0.0" {
+		t.Errorf("expected version v1.0.0, got %q", pkg.Version)
+	}
+
+	_, err = s.Search(ctx, "example.com/missing")
+	if !errors.Is(err, doc.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
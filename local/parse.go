@@ -0,0 +1,277 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	godoc "go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hhhapz/doc"
+)
+
+type state struct {
+	fset    *token.FileSet
+	docPkg  *godoc.Package
+	pkg     doc.Package
+	useCase bool
+}
+
+// parse reads the Go files in dir, including _test.go files for examples, and
+// converts the go/doc representation into a doc.Package.
+func parse(dir, importPath string, useCase, dupeTypeFuncs bool) (doc.Package, error) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return doc.Package{}, err
+	}
+
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return doc.Package{}, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return doc.Package{}, fmt.Errorf("%w: no go files in %s", doc.ErrNotFound, dir)
+	}
+
+	p, err := godoc.NewFromFiles(fset, files, importPath)
+	if err != nil {
+		return doc.Package{}, err
+	}
+
+	s := &state{
+		fset:   fset,
+		docPkg: p,
+		pkg: doc.Package{
+			URL:         importPath,
+			Name:        p.Name,
+			ConstantMap: map[string]doc.Variable{},
+			VariableMap: map[string]doc.Variable{},
+			Functions:   map[string]doc.Function{},
+			Types:       map[string]doc.Type{},
+			Subpackages: subpackages(dir, importPath),
+		},
+		useCase: useCase,
+	}
+	s.pkg.Overview = s.comment(p.Doc)
+	s.pkg.Examples = s.examples(p.Examples)
+
+	s.values(p.Consts, true)
+	s.values(p.Vars, false)
+
+	for _, f := range p.Funcs {
+		put(s.pkg.Functions, f.Name, s.function(f), useCase)
+	}
+
+	for _, t := range p.Types {
+		s.values(t.Consts, true)
+		s.values(t.Vars, false)
+
+		typ := doc.Type{
+			Name:          t.Name,
+			Signature:     s.print(stripDoc(t.Decl)),
+			Comment:       s.comment(t.Doc),
+			Examples:      s.examples(t.Examples),
+			TypeFunctions: map[string]doc.Function{},
+			Methods:       map[string]doc.Method{},
+		}
+		// the declaration was printed from valid source, so it always parses.
+		s.pkg.ParseType(&typ, "")
+		for _, f := range t.Funcs {
+			fn := s.function(f)
+			if dupeTypeFuncs {
+				put(s.pkg.Functions, f.Name, fn, useCase)
+			}
+			put(typ.TypeFunctions, f.Name, fn, useCase)
+		}
+		for _, m := range t.Methods {
+			put(typ.Methods, m.Name, doc.Method{
+				For:      t.Name,
+				Function: s.function(m),
+			}, useCase)
+		}
+		put(s.pkg.Types, t.Name, typ, useCase)
+	}
+
+	return s.pkg, nil
+}
+
+func (s *state) values(values []*godoc.Value, constants bool) {
+	m := s.pkg.VariableMap
+	if constants {
+		m = s.pkg.ConstantMap
+	}
+
+	for _, value := range values {
+		v := doc.Variable{
+			Signature: s.print(stripDoc(value.Decl)),
+			Comment:   s.comment(value.Doc),
+		}
+		if constants {
+			s.pkg.Constants = append(s.pkg.Constants, v)
+		} else {
+			s.pkg.Variables = append(s.pkg.Variables, v)
+		}
+
+		for _, name := range value.Names {
+			v.Name = name
+			put(m, name, v, s.useCase)
+		}
+	}
+}
+
+func (s *state) function(f *godoc.Func) doc.Function {
+	decl := *f.Decl
+	decl.Doc = nil
+	decl.Body = nil
+
+	fn := doc.Function{
+		Name:      f.Name,
+		Signature: s.print(&decl),
+		Comment:   s.comment(f.Doc),
+		Examples:  s.examples(f.Examples),
+	}
+	// the declaration was printed from valid source, so it always parses.
+	s.pkg.ParseFunction(&fn, "")
+	return fn
+}
+
+func (s *state) examples(examples []*godoc.Example) []doc.Example {
+	if len(examples) == 0 {
+		return nil
+	}
+
+	result := make([]doc.Example, 0, len(examples))
+	for _, ex := range examples {
+		name := "Example"
+		if ex.Suffix != "" {
+			name += " (" + ex.Suffix + ")"
+		}
+
+		code := printer.CommentedNode{Node: ex.Code, Comments: ex.Comments}
+		result = append(result, doc.Example{
+			Name:   name,
+			Code:   exampleBody(s.printNode(&code)),
+			Output: ex.Output,
+		})
+	}
+	return result
+}
+
+// comment converts a doc comment into a doc.Comment, using the doc comment
+// syntax introduced in Go 1.19.
+func (s *state) comment(text string) doc.Comment {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	return doc.NewComment(s.docPkg.Parser().Parse(text))
+}
+
+// print formats a declaration, keeping the comments of the fields and specs
+// that are inside of it.
+func (s *state) print(node ast.Node) string {
+	var comments []*ast.CommentGroup
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			comments = appendGroups(comments, n.Doc, n.Comment)
+		case *ast.ValueSpec:
+			comments = appendGroups(comments, n.Doc, n.Comment)
+		case *ast.TypeSpec:
+			comments = appendGroups(comments, n.Doc, n.Comment)
+		}
+		return true
+	})
+	return s.printNode(&printer.CommentedNode{Node: node, Comments: comments})
+}
+
+func appendGroups(comments []*ast.CommentGroup, groups ...*ast.CommentGroup) []*ast.CommentGroup {
+	for _, g := range groups {
+		if g != nil {
+			comments = append(comments, g)
+		}
+	}
+	return comments
+}
+
+func (s *state) printNode(node any) string {
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(&buf, s.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// stripDoc returns a copy of decl without its doc comment, which is already
+// available through the Comment fields.
+func stripDoc(decl *ast.GenDecl) *ast.GenDecl {
+	d := *decl
+	d.Doc = nil
+	return &d
+}
+
+// exampleBody removes the surrounding braces of an example function body and
+// the indentation that came with them.
+func exampleBody(code string) string {
+	if !strings.HasPrefix(code, "{") || !strings.HasSuffix(code, "}") {
+		return code
+	}
+	code = strings.TrimSpace(code[1 : len(code)-1])
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "\t")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// subpackages lists the import paths of all packages nested in dir.
+func subpackages(dir, importPath string) []string {
+	var pkgs []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == dir {
+			return nil
+		}
+		name := d.Name()
+		if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			return filepath.SkipDir
+		}
+		if isPackage(path) {
+			rel, _ := filepath.Rel(dir, path)
+			pkgs = append(pkgs, importPath+"/"+filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+func put[V any](m map[string]V, name string, v V, useCase bool) {
+	if !useCase {
+		name = strings.ToLower(name)
+	}
+	m[name] = v
+}
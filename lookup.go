@@ -0,0 +1,232 @@
+package doc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSymbolNotFound is returned by Package.Lookup when no symbol matches the
+// query.
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// AmbiguousSymbolError is returned by Package.Lookup when the query matches
+// more than one symbol, and none of them matches it exactly.
+type AmbiguousSymbolError struct {
+	Query   string
+	Matches []Symbol
+}
+
+// Error satisfies the error interface.
+func (err *AmbiguousSymbolError) Error() string {
+	names := make([]string, 0, len(err.Matches))
+	for _, s := range err.Matches {
+		names = append(names, s.FullName())
+	}
+	return fmt.Sprintf("ambiguous symbol %q, could be: %s", err.Query, strings.Join(names, ", "))
+}
+
+// SymbolKind is the kind of declaration a Symbol refers to.
+type SymbolKind int
+
+const (
+	ConstSymbol SymbolKind = iota + 1
+	VarSymbol
+	FuncSymbol
+	TypeSymbol
+	MethodSymbol
+)
+
+// String returns the keyword used to declare the kind of symbol.
+func (k SymbolKind) String() string {
+	switch k {
+	case ConstSymbol:
+		return "const"
+	case VarSymbol:
+		return "var"
+	case FuncSymbol, MethodSymbol:
+		return "func"
+	case TypeSymbol:
+		return "type"
+	}
+	return "unknown"
+}
+
+// Symbol is a single declaration in a package, as returned by Package.Lookup.
+type Symbol struct {
+	Kind SymbolKind `json:"kind"`
+	Name string     `json:"name"`
+	// Parent is the name of the type that a method or a function returning
+	// the type belongs to. It is empty for all other symbols.
+	Parent    string    `json:"parent"`
+	Signature string    `json:"signature"`
+	Comment   Comment   `json:"comment"`
+	Examples  []Example `json:"examples"`
+}
+
+// FullName returns the name of the symbol, qualified by its parent type for
+// methods, such as Buffer.WriteString.
+func (s Symbol) FullName() string {
+	if s.Kind == MethodSymbol {
+		return s.Parent + "." + s.Name
+	}
+	return s.Name
+}
+
+// MatchMode controls how Package.Lookup compares names to the query.
+type MatchMode int
+
+const (
+	// MatchExact only matches names that are identical to the query.
+	MatchExact MatchMode = iota
+	// MatchFold matches names that are equal to the query under Unicode
+	// case-folding.
+	MatchFold
+	// MatchPrefix matches names that start with the query, ignoring case.
+	MatchPrefix
+)
+
+func (m MatchMode) match(name, query string) bool {
+	switch m {
+	case MatchFold:
+		return strings.EqualFold(name, query)
+	case MatchPrefix:
+		return len(name) >= len(query) && strings.EqualFold(name[:len(query)], query)
+	default:
+		return name == query
+	}
+}
+
+// Lookup finds a single symbol in the package. The query may be the name of a
+// constant, variable, function or type, or Type.Method for methods and
+// functions returning a type.
+//
+// Lookup works the same regardless of whether the package was searched with
+// MaintainCase. If more than one symbol matches the query, the one whose name
+// is identical to the query is returned, or else the only one whose name is
+// equal to the query ignoring case, and an *AmbiguousSymbolError otherwise. If
+// no symbol matches, ErrSymbolNotFound is returned.
+func (p Package) Lookup(query string, mode MatchMode) (Symbol, error) {
+	matches := p.LookupAll(query, mode)
+	switch len(matches) {
+	case 0:
+		return Symbol{}, fmt.Errorf("%w: %s", ErrSymbolNotFound, query)
+	case 1:
+		return matches[0], nil
+	}
+
+	for _, s := range matches {
+		if s.FullName() == query {
+			return s, nil
+		}
+	}
+	var folded []Symbol
+	for _, s := range matches {
+		if strings.EqualFold(s.FullName(), query) {
+			folded = append(folded, s)
+		}
+	}
+	if len(folded) == 1 {
+		return folded[0], nil
+	}
+	return Symbol{}, &AmbiguousSymbolError{Query: query, Matches: matches}
+}
+
+// LookupAll returns all symbols in the package that match the query, sorted by
+// name. See Lookup for the accepted queries.
+func (p Package) LookupAll(query string, mode MatchMode) []Symbol {
+	var matches []Symbol
+	typeName, member, ok := strings.Cut(query, ".")
+	if ok {
+		typeMode := mode
+		if typeMode == MatchPrefix {
+			typeMode = MatchFold
+		}
+		for _, t := range p.Types {
+			if !typeMode.match(t.Name, typeName) {
+				continue
+			}
+			for _, m := range t.Methods {
+				if mode.match(m.Name, member) {
+					matches = append(matches, funcSymbol(MethodSymbol, t.Name, m.Function))
+				}
+			}
+			for _, f := range t.TypeFunctions {
+				if mode.match(f.Name, member) {
+					matches = append(matches, funcSymbol(FuncSymbol, t.Name, f))
+				}
+			}
+		}
+		return sortSymbols(matches)
+	}
+
+	for _, v := range p.ConstantMap {
+		if mode.match(v.Name, query) {
+			matches = append(matches, varSymbol(ConstSymbol, v))
+		}
+	}
+	for _, v := range p.VariableMap {
+		if mode.match(v.Name, query) {
+			matches = append(matches, varSymbol(VarSymbol, v))
+		}
+	}
+
+	// functions returning a type may also be present in p.Functions when
+	// WithDuplicateTypeFuncs is used, prefer the entry with the parent type.
+	typeFuncs := map[string]bool{}
+	for _, t := range p.Types {
+		if mode.match(t.Name, query) {
+			matches = append(matches, Symbol{
+				Kind:      TypeSymbol,
+				Name:      t.Name,
+				Signature: t.Signature,
+				Comment:   t.Comment,
+				Examples:  t.Examples,
+			})
+		}
+		for _, f := range t.TypeFunctions {
+			if mode.match(f.Name, query) {
+				typeFuncs[f.Name] = true
+				matches = append(matches, funcSymbol(FuncSymbol, t.Name, f))
+			}
+		}
+	}
+	for _, f := range p.Functions {
+		if mode.match(f.Name, query) && !typeFuncs[f.Name] {
+			matches = append(matches, funcSymbol(FuncSymbol, "", f))
+		}
+	}
+	return sortSymbols(matches)
+}
+
+func varSymbol(kind SymbolKind, v Variable) Symbol {
+	return Symbol{
+		Kind:      kind,
+		Name:      v.Name,
+		Signature: v.Signature,
+		Comment:   v.Comment,
+	}
+}
+
+func funcSymbol(kind SymbolKind, parent string, f Function) Symbol {
+	return Symbol{
+		Kind:      kind,
+		Name:      f.Name,
+		Parent:    parent,
+		Signature: f.Signature,
+		Comment:   f.Comment,
+		Examples:  f.Examples,
+	}
+}
+
+func sortSymbols(symbols []Symbol) []Symbol {
+	sort.Slice(symbols, func(i, j int) bool {
+		a, b := symbols[i], symbols[j]
+		if a.FullName() != b.FullName() {
+			return a.FullName() < b.FullName()
+		}
+		return a.Kind < b.Kind
+	})
+	return symbols
+}
@@ -0,0 +1,74 @@
+package doc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hhhapz/doc"
+)
+
+var lookupPackage = doc.Package{
+	Name:        "bytes",
+	ConstantMap: map[string]doc.Variable{"minread": {Name: "MinRead"}},
+	VariableMap: map[string]doc.Variable{"errtoolarge": {Name: "ErrTooLarge"}},
+	Functions: map[string]doc.Function{
+		"compare":   {Name: "Compare"},
+		"newbuffer": {Name: "NewBuffer"},
+	},
+	Types: map[string]doc.Type{
+		"buffer": {
+			Name:          "Buffer",
+			TypeFunctions: map[string]doc.Function{"newbuffer": {Name: "NewBuffer"}},
+			Methods: map[string]doc.Method{
+				"write":       {For: "Buffer", Function: doc.Function{Name: "Write"}},
+				"writestring": {For: "Buffer", Function: doc.Function{Name: "WriteString"}},
+			},
+		},
+	},
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		query  string
+		mode   doc.MatchMode
+		kind   doc.SymbolKind
+		name   string
+		parent string
+	}{
+		{"MinRead", doc.MatchExact, doc.ConstSymbol, "MinRead", ""},
+		{"errtoolarge", doc.MatchFold, doc.VarSymbol, "ErrTooLarge", ""},
+		{"Compare", doc.MatchExact, doc.FuncSymbol, "Compare", ""},
+		{"NewBuffer", doc.MatchExact, doc.FuncSymbol, "NewBuffer", "Buffer"},
+		{"buffer", doc.MatchFold, doc.TypeSymbol, "Buffer", ""},
+		{"Buffer.WriteString", doc.MatchExact, doc.MethodSymbol, "WriteString", "Buffer"},
+		{"buffer.writes", doc.MatchPrefix, doc.MethodSymbol, "WriteString", "Buffer"},
+		{"Buffer.Write", doc.MatchPrefix, doc.MethodSymbol, "Write", "Buffer"},
+		{"buffer.write", doc.MatchPrefix, doc.MethodSymbol, "Write", "Buffer"},
+		{"newbuffer", doc.MatchFold, doc.FuncSymbol, "NewBuffer", "Buffer"},
+	}
+
+	for _, tt := range tests {
+		s, err := lookupPackage.Lookup(tt.query, tt.mode)
+		if err != nil {
+			t.Errorf("Lookup(%q): unexpected error: %v", tt.query, err)
+			continue
+		}
+		if s.Kind != tt.kind || s.Name != tt.name || s.Parent != tt.parent {
+			t.Errorf("Lookup(%q) = %v %s (parent %q), want %v %s (parent %q)",
+				tt.query, s.Kind, s.Name, s.Parent, tt.kind, tt.name, tt.parent)
+		}
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	if _, err := lookupPackage.Lookup("minread", doc.MatchExact); !errors.Is(err, doc.ErrSymbolNotFound) {
+		t.Errorf("expected ErrSymbolNotFound, got %v", err)
+	}
+
+	var ambiguous *doc.AmbiguousSymbolError
+	if _, err := lookupPackage.Lookup("buffer.w", doc.MatchPrefix); !errors.As(err, &ambiguous) {
+		t.Errorf("expected AmbiguousSymbolError, got %v", err)
+	} else if len(ambiguous.Matches) != 2 {
+		t.Errorf("expected 2 matches, got %d", len(ambiguous.Matches))
+	}
+}
@@ -0,0 +1,179 @@
+package doc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metrics collects request and parse metrics from searchers, and serves them in
+// the Prometheus text format. A Metrics may be shared by any number of
+// searchers, by passing it to WithMetrics.
+//
+// Metrics implements http.Handler, so that it can be mounted on a mux:
+//
+//	m := doc.NewMetrics()
+//	s := doc.NewSearcher(pkgsite.Parser, doc.WithMetrics(m))
+//	mux.Handle("/metrics", m)
+type Metrics struct {
+	mu       sync.Mutex
+	requests map[requestKey]uint64
+	hosts    map[string]*hostMetrics
+}
+
+type requestKey struct {
+	host   string
+	status string
+}
+
+type hostMetrics struct {
+	bytes           int64
+	requestSeconds  float64
+	requestCount    uint64
+	parseSeconds    float64
+	parseCount      uint64
+	parseErrorCount uint64
+}
+
+// NewMetrics creates an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: map[requestKey]uint64{},
+		hosts:    map[string]*hostMetrics{},
+	}
+}
+
+// WithMetrics records the requests and parses of the searcher in m, using the
+// OnResponse and OnParse hooks.
+func WithMetrics(m *Metrics) SearchOption {
+	return func(s *httpSearcher) {
+		OnResponse(m.response)(s)
+		OnParse(m.parse)(s)
+	}
+}
+
+func (m *Metrics) host(host string) *hostMetrics {
+	hm, ok := m.hosts[host]
+	if !ok {
+		hm = &hostMetrics{}
+		m.hosts[host] = hm
+	}
+	return hm
+}
+
+func (m *Metrics) response(_ context.Context, info ResponseInfo) {
+	host := source(info.URL)
+	status := "error"
+	if info.Status != 0 {
+		status = strconv.Itoa(info.Status)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{host, status}]++
+	hm := m.host(host)
+	hm.bytes += info.Bytes
+	hm.requestSeconds += info.Duration.Seconds()
+	hm.requestCount++
+}
+
+func (m *Metrics) parse(_ context.Context, info ParseInfo) {
+	host := source(info.URL)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hm := m.host(host)
+	hm.parseSeconds += info.Duration.Seconds()
+	hm.parseCount++
+	if info.Err != nil {
+		hm.parseErrorCount++
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format to w.
+// Searchers are not blocked from recording metrics while w is written to.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.format())
+	return int64(n), err
+}
+
+// format formats a snapshot of the metrics in the Prometheus text exposition
+// format.
+func (m *Metrics) format() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].host != keys[j].host {
+			return keys[i].host < keys[j].host
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	hosts := make([]string, 0, len(m.hosts))
+	for host := range m.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	metricHeader(&b, "doc_requests_total", "counter", "Requests made by searchers, by host and status.")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "doc_requests_total{host=\"%s\",status=\"%s\"} %d\n", escapeLabel(k.host), k.status, m.requests[k])
+	}
+
+	metricHeader(&b, "doc_response_bytes_total", "counter", "Bytes read from successful responses.")
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "doc_response_bytes_total{host=\"%s\"} %d\n", escapeLabel(host), m.hosts[host].bytes)
+	}
+
+	metricHeader(&b, "doc_request_duration_seconds", "summary", "Time taken to make requests and read their body.")
+	for _, host := range hosts {
+		hm := m.hosts[host]
+		fmt.Fprintf(&b, "doc_request_duration_seconds_sum{host=\"%s\"} %g\n", escapeLabel(host), hm.requestSeconds)
+		fmt.Fprintf(&b, "doc_request_duration_seconds_count{host=\"%s\"} %d\n", escapeLabel(host), hm.requestCount)
+	}
+
+	metricHeader(&b, "doc_parse_duration_seconds", "summary", "Time taken to parse documentation pages.")
+	for _, host := range hosts {
+		hm := m.hosts[host]
+		fmt.Fprintf(&b, "doc_parse_duration_seconds_sum{host=\"%s\"} %g\n", escapeLabel(host), hm.parseSeconds)
+		fmt.Fprintf(&b, "doc_parse_duration_seconds_count{host=\"%s\"} %d\n", escapeLabel(host), hm.parseCount)
+	}
+
+	metricHeader(&b, "doc_parse_errors_total", "counter", "Documentation pages that could not be parsed.")
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "doc_parse_errors_total{host=\"%s\"} %d\n", escapeLabel(host), m.hosts[host].parseErrorCount)
+	}
+
+	return b.String()
+}
+
+func metricHeader(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// labelEscaper escapes label values as required by the Prometheus format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,134 @@
+package doc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// Middleware wraps a Searcher to add behaviour around each search, such as
+// logging, timeouts or caching.
+type Middleware func(Searcher) Searcher
+
+// SearcherFunc is an adapter to use an ordinary function as a Searcher.
+type SearcherFunc func(ctx context.Context, module string) (Package, error)
+
+// Search calls f(ctx, module).
+func (f SearcherFunc) Search(ctx context.Context, module string) (Package, error) {
+	return f(ctx, module)
+}
+
+// Chain combines middlewares into a single Middleware. The first middleware is
+// the outermost one, so Chain(a, b)(s) is equivalent to a(b(s)).
+func Chain(middlewares ...Middleware) Middleware {
+	return func(s Searcher) Searcher {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			s = middlewares[i](s)
+		}
+		return s
+	}
+}
+
+// Logging logs every search to logger, with the module, how long the search
+// took, and the resulting version and source or error. Successful searches are
+// logged at the debug level, failed searches at the warn level. If logger is
+// nil, slog.Default is used.
+func Logging(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(s Searcher) Searcher {
+		return SearcherFunc(func(ctx context.Context, module string) (Package, error) {
+			start := time.Now()
+			pkg, err := s.Search(ctx, module)
+			if err != nil {
+				logger.WarnContext(ctx, "search failed",
+					slog.String("module", module),
+					slog.Duration("duration", time.Since(start)),
+					slog.Any("error", err),
+				)
+				return pkg, err
+			}
+
+			logger.DebugContext(ctx, "search",
+				slog.String("module", module),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("version", pkg.Version),
+				slog.String("source", pkg.Source),
+			)
+			return pkg, nil
+		})
+	}
+}
+
+// Timeout limits each search to d. If d is not positive, searches are not
+// limited.
+func Timeout(d time.Duration) Middleware {
+	return func(s Searcher) Searcher {
+		if d <= 0 {
+			return s
+		}
+		return SearcherFunc(func(ctx context.Context, module string) (Package, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return s.Search(ctx, module)
+		})
+	}
+}
+
+// PanicError is returned by searchers wrapped with Recover when the search
+// panicked.
+type PanicError struct {
+	// Value is the value the searcher panicked with.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error satisfies the error interface.
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("search panicked: %v", err.Value)
+}
+
+// Unwrap returns the value the searcher panicked with, if it is an error.
+func (err *PanicError) Unwrap() error {
+	if e, ok := err.Value.(error); ok {
+		return e
+	}
+	return nil
+}
+
+// Recover recovers from panics during a search, returning a *PanicError
+// instead.
+func Recover() Middleware {
+	return func(s Searcher) Searcher {
+		return SearcherFunc(func(ctx context.Context, module string) (pkg Package, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					pkg, err = Package{}, &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return s.Search(ctx, module)
+		})
+	}
+}
+
+// Cache caches the results of the wrapped searcher. The cache is configured
+// with the cache options WithStore, WithMaxEntries, WithMaxAge,
+// WithStaleWhileRevalidate, WithNotFoundTTL and WithJanitor; other options are
+// ignored.
+//
+// The returned searchers implement CachedSearcher. If the wrapped searcher was
+// created with NewSearcher, expired packages are revalidated with conditional
+// requests.
+func Cache(opts ...SearchOption) Middleware {
+	var config httpSearcher
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return func(s Searcher) Searcher {
+		return newCachedSearcher(s, config.cache)
+	}
+}
@@ -0,0 +1,105 @@
+package doc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(s Searcher) Searcher {
+			return SearcherFunc(func(ctx context.Context, module string) (Package, error) {
+				order = append(order, name)
+				return s.Search(ctx, module)
+			})
+		}
+	}
+
+	s := Chain(record("a"), record("b"), record("c"))(&countingSearcher{})
+	if _, err := s.Search(context.Background(), "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "a,b,c" {
+		t.Errorf("expected middlewares to run in order a,b,c, got %s", got)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	s := Logging(logger)(&countingSearcher{})
+	if _, err := s.Search(context.Background(), "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "module=pkg") || !strings.Contains(out, "duration=") {
+		t.Errorf("expected module and duration to be logged, got %q", out)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s := Timeout(10 * time.Millisecond)(SearcherFunc(func(ctx context.Context, _ string) (Package, error) {
+		<-ctx.Done()
+		return Package{}, ctx.Err()
+	}))
+
+	if _, err := s.Search(context.Background(), "pkg"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	s := Recover()(SearcherFunc(func(context.Context, string) (Package, error) {
+		panic("boom")
+	}))
+
+	_, err := s.Search(context.Background(), "pkg")
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected panic error, got %v", err)
+	}
+	if len(panicErr.Stack) == 0 {
+		t.Errorf("expected stack trace")
+	}
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	counter := &countingSearcher{}
+	s := Chain(Recover(), Cache(WithMaxEntries(1)))(counter)
+	for range 3 {
+		if _, err := s.Search(context.Background(), "pkg"); err != nil {
+			t.Fatalf("could not search: %v", err)
+		}
+	}
+	if n := counter.calls.Load(); n != 1 {
+		t.Errorf("expected 1 search, got %d", n)
+	}
+}
+
+func TestCacheMiddlewareRecover(t *testing.T) {
+	var calls atomic.Int64
+	s := Chain(Recover(), Cache())(SearcherFunc(func(context.Context, string) (Package, error) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		return Package{Name: "pkg"}, nil
+	}))
+
+	_, err := s.Search(context.Background(), "pkg")
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected panic error, got %v", err)
+	}
+
+	// the panic is not cached.
+	if pkg, err := s.Search(context.Background(), "pkg"); err != nil || pkg.Name != "pkg" {
+		t.Errorf("expected search to succeed after the panic, got %q, %v", pkg.Name, err)
+	}
+}
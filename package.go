@@ -6,8 +6,17 @@ import (
 )
 
 type Package struct {
-	URL      string    `json:"url"`
-	Name     string    `json:"name"`
+	// SchemaVersion is the version of the JSON encoding the package was
+	// decoded from. It is set to the current SchemaVersion when encoding a
+	// package.
+	SchemaVersion int `json:"schema_version"`
+
+	URL     string `json:"url"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Source is the site or searcher the package was retrieved from, such as
+	// pkg.go.dev.
+	Source   string    `json:"source"`
 	Overview Comment   `json:"overview"`
 	Examples []Example `json:"examples"`
 
@@ -21,6 +30,10 @@ type Package struct {
 	Types     map[string]Type     `json:"types"`
 
 	Subpackages []string `json:"subpackages"`
+
+	// Diagnostics lists anything the parser skipped or could not find on the
+	// page.
+	Diagnostics []Diagnostic `json:"diagnostics"`
 }
 
 type Variable struct {
@@ -30,19 +43,29 @@ type Variable struct {
 }
 
 type Function struct {
-	Name      string    `json:"name"`
-	Signature string    `json:"signature"`
-	Comment   Comment   `json:"comment"`
-	Examples  []Example `json:"examples"`
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	// Parsed is the parsed form of Signature, or nil if the signature could
+	// not be parsed.
+	Parsed   *Signature `json:"parsed"`
+	Comment  Comment    `json:"comment"`
+	Examples []Example  `json:"examples"`
 }
 
 type Type struct {
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
+	Name string `json:"name"`
+	// Type is the kind of the type, such as StructType.
+	Type      TypeKind  `json:"type"`
 	Signature string    `json:"signature"`
 	Comment   Comment   `json:"comment"`
 	Examples  []Example `json:"examples"`
 
+	// Fields are the fields of a struct type, in the order they are declared.
+	Fields []Field `json:"fields"`
+	// InterfaceMethods are the methods and embedded types of an interface
+	// type, in the order they are declared.
+	InterfaceMethods []InterfaceMethod `json:"interface_methods"`
+
 	TypeFunctions map[string]Function `json:"type_functions"`
 	Methods       map[string]Method   `json:"methods"`
 }
@@ -7,57 +7,98 @@ import (
 	"github.com/hhhapz/doc"
 )
 
-type ParseError struct {
-	Sel     *goquery.Selection
-	Message string
-}
-
-func (err ParseError) Error() string {
-	return err.Message
-}
+// ParseError is the error returned when the page could not be parsed. It is
+// an alias of doc.ParseError, which is shared by all parsers.
+type ParseError = doc.ParseError
 
 type state struct {
 	doc     *goquery.Document
 	pkg     doc.Package
 	current *doc.Type
 	useCase bool
+	// page is the url of the page, used to resolve links.
+	page string
 }
 
 func newState(document *goquery.Document, useCase bool) (*state, error) {
+	s := &state{
+		doc:     document,
+		useCase: useCase,
+	}
+
 	name := document.Find("h1.UnitHeader-titleHeading").Text()
 
+	if document.Find("div.UnitDoc").Length() == 0 {
+		s.pkg.Diagnose(doc.MissingSection, "div.UnitDoc", "no documentation")
+	}
+	url := document.Find("nav.go-Breadcrumb ol li").Last().Find("a").AttrOr("href", "")
+	url, _ = doc.SplitVersion(strings.TrimPrefix(url, "/"))
+	if url == "" {
+		s.pkg.Diagnose(doc.MissingSection, "nav.go-Breadcrumb", "no import path")
+	}
+	version := document.Find(`[data-test-id="UnitHeader-version"] a`).First().Text()
+	version = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(version), "Version:"))
+	s.page = base + doc.JoinVersion(url, version)
+
 	sel := document.Find("div.UnitDoc .Documentation-overview")
-	overview := comments(sel.Children().NextUntil("details"))
+	overview := s.comments(sel.Children().NextUntil("details"))
 	examples := examples(sel)
-	url := document.Find("nav.go-Breadcrumb ol li").Last().Find("a").AttrOr("href", "/")[1:]
 
 	subpkgs := subpackages(document)
 
-	return &state{
-		doc: document,
-		pkg: doc.Package{
-			URL:         url,
-			Name:        name,
-			Overview:    overview,
-			Examples:    examples,
-			ConstantMap: map[string]doc.Variable{},
-			VariableMap: map[string]doc.Variable{},
-			Functions:   map[string]doc.Function{},
-			Types:       map[string]doc.Type{},
-			Subpackages: subpkgs,
-		},
-		useCase: useCase,
-	}, nil
+	s.pkg = doc.Package{
+		URL:         url,
+		Name:        name,
+		Version:     version,
+		Overview:    overview,
+		Examples:    examples,
+		ConstantMap: map[string]doc.Variable{},
+		VariableMap: map[string]doc.Variable{},
+		Functions:   map[string]doc.Function{},
+		Types:       map[string]doc.Type{},
+		Subpackages: subpkgs,
+		Diagnostics: s.pkg.Diagnostics,
+	}
+	return s, nil
+}
+
+func (s *state) newError(sel *goquery.Selection, selector, msg string) error {
+	return &ParseError{
+		Parser:   "pkgsite",
+		Selector: selector,
+		Sel:      sel,
+		Message:  msg,
+	}
 }
 
-func (s *state) newError(sel *goquery.Selection, msg string) error {
-	return ParseError{sel, msg}
+// declaration records a diagnostic if the name or signature of a declaration
+// found with selector is empty.
+func (s *state) declaration(selector, name, signature string) {
+	switch {
+	case name == "":
+		s.pkg.Diagnose(doc.EmptySignature, selector, "no name")
+	case signature == "":
+		s.pkg.Diagnose(doc.EmptySignature, selector, "no signature for "+name)
+	}
+}
+
+// section returns the section matching selector, and records a diagnostic if
+// it is missing.
+func (s *state) section(selector string) *goquery.Selection {
+	sel := s.doc.Find(selector)
+	if sel.Length() == 0 {
+		s.pkg.Diagnose(doc.MissingSection, selector, "")
+	}
+	return sel
 }
 
 func (s *state) variables(sel *goquery.Selection, constants bool, m map[string]doc.Variable) error {
 	sel.Filter(".Documentation-declaration").Each(func(i int, sel *goquery.Selection) {
-		comment := comments(sel.NextUntil(".Documentation-declaration"))
+		comment := s.comments(sel.NextUntil(".Documentation-declaration"))
 		signature := sel.Find("pre").Text()
+		if strings.TrimSpace(signature) == "" {
+			s.pkg.Diagnose(doc.EmptySignature, ".Documentation-declaration", "no signature")
+		}
 		v := doc.Variable{
 			Signature: signature,
 			Comment:   comment,
@@ -67,7 +108,11 @@ func (s *state) variables(sel *goquery.Selection, constants bool, m map[string]d
 		} else {
 			s.pkg.Variables = append(s.pkg.Variables, v)
 		}
-		sel.Find("span[data-kind]").Each(func(i int, nameSel *goquery.Selection) {
+		names := sel.Find("span[data-kind]")
+		if names.Length() == 0 {
+			s.pkg.Diagnose(doc.EmptySignature, ".Documentation-declaration span[data-kind]", "no names")
+		}
+		names.Each(func(i int, nameSel *goquery.Selection) {
 			name := nameSel.AttrOr("id", "")
 			v := doc.Variable{
 				Name:      name,
@@ -87,12 +132,14 @@ func (s *state) functions(sel *goquery.Selection) error {
 	sel.Each(func(i int, sel *goquery.Selection) {
 		name := sel.Find(header).First().Text()
 		decl := sel.Find("div.Documentation-declaration")
-		comment := comments(decl.NextUntil("details"))
+		comment := s.comments(decl.NextUntil("details"))
 		f := doc.Function{
 			Name:      name,
 			Signature: strings.TrimSpace(decl.Text()),
 			Comment:   comment,
 		}
+		s.declaration(base, f.Name, f.Signature)
+		s.pkg.ParseFunction(&f, base)
 		put(s.pkg.Functions, name, f, s.useCase)
 	})
 	return nil
@@ -103,7 +150,7 @@ func (s *state) typ(sel *goquery.Selection) (doc.Type, error) {
 
 	name := sel.Find(header).First().Text()
 	decl := sel.Find("div.Documentation-declaration").First()
-	comment := comments(decl.NextUntil("details, .Documentation-typeFunc, .Documentation-typeMethod"))
+	comment := s.comments(decl.NextUntil("details, .Documentation-typeFunc, .Documentation-typeMethod"))
 	t := doc.Type{
 		Name:          name,
 		Signature:     strings.TrimSpace(decl.Text()),
@@ -111,6 +158,8 @@ func (s *state) typ(sel *goquery.Selection) (doc.Type, error) {
 		TypeFunctions: map[string]doc.Function{},
 		Methods:       map[string]doc.Method{},
 	}
+	s.declaration(".Documentation-type", t.Name, t.Signature)
+	s.pkg.ParseType(&t, ".Documentation-type")
 	put(s.pkg.Types, name, t, s.useCase)
 	return t, nil
 }
@@ -120,12 +169,14 @@ func (s *state) typefuncs(sel *goquery.Selection, m map[string]doc.Function, dup
 
 	name := sel.Find(header).First().Text()
 	decl := sel.Find("div.Documentation-declaration").First()
-	comment := comments(decl.NextUntil("details, .Documentation-typeFunc, .Documentation-typeMethod"))
+	comment := s.comments(decl.NextUntil("details, .Documentation-typeFunc, .Documentation-typeMethod"))
 	f := doc.Function{
 		Name:      name,
 		Signature: strings.TrimSpace(decl.Text()),
 		Comment:   comment,
 	}
+	s.declaration(".Documentation-typeFunc", f.Name, f.Signature)
+	s.pkg.ParseFunction(&f, ".Documentation-typeFunc")
 	if dupe {
 		put(s.pkg.Functions, name, f, s.useCase)
 	}
@@ -138,7 +189,7 @@ func (s *state) methods(sel *goquery.Selection, forType string, m map[string]doc
 
 	name := sel.Find(header).First().Text()
 	decl := sel.Find("div.Documentation-declaration").First()
-	comment := comments(decl.NextUntil("details, .Documentation-typeFunc, .Documentation-typeMethod"))
+	comment := s.comments(decl.NextUntil("details, .Documentation-typeFunc, .Documentation-typeMethod"))
 	mtd := doc.Method{
 		For: forType,
 		Function: doc.Function{
@@ -147,6 +198,8 @@ func (s *state) methods(sel *goquery.Selection, forType string, m map[string]doc
 			Comment:   comment,
 		},
 	}
+	s.declaration(".Documentation-typeMethod", mtd.Name, mtd.Signature)
+	s.pkg.ParseFunction(&mtd.Function, ".Documentation-typeMethod")
 	put(m, name, mtd, s.useCase)
 	return nil
 }
@@ -157,25 +210,27 @@ func subpackages(doc *goquery.Document) []string {
 	return nil
 }
 
-func comments(sel *goquery.Selection) doc.Comment {
+func (s *state) comments(sel *goquery.Selection) doc.Comment {
 	if sel.Length() == 0 {
 		return nil
 	}
 	comments := make(doc.Comment, 0, len(sel.Nodes))
-	sel.Each(func(i int, s *goquery.Selection) {
-		n := s.Nodes[0]
+	sel.Each(func(i int, item *goquery.Selection) {
+		n := item.Nodes[0]
 		switch n.Data {
 		case "p":
-			f := strings.Fields(s.Text())
-			comments = append(comments, doc.Paragraph(strings.Join(f, " ")))
+			comments = append(comments, doc.ParseParagraph(item, s.page))
 		case "pre":
-			comments = append(comments, doc.Pre(s.Text()))
+			comments = append(comments, doc.Pre(item.Text()))
 		case "h4":
-			if s.AttrOr("id", "") == "" {
+			if item.AttrOr("id", "") == "" || n.FirstChild == nil {
+				s.pkg.Diagnose(doc.UnrecognizedNode, "h4", "skipped heading without id: "+strings.TrimSpace(item.Text()))
 				return
 			}
 			text := strings.TrimSpace(n.FirstChild.Data)
 			comments = append(comments, doc.Heading(text))
+		case "ul", "ol":
+			comments = append(comments, doc.ParseList(item, s.page))
 		}
 	})
 	return comments
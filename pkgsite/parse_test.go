@@ -0,0 +1,211 @@
+package pkgsite_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/hhhapz/doc"
+	"github.com/hhhapz/doc/pkgsite"
+)
+
+func parseFile(t *testing.T, name string) (doc.Package, error) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("could not read fixture: %v", err)
+	}
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("could not parse html: %v", err)
+	}
+	return pkgsite.Parser.Parse(document, false, false)
+}
+
+func TestParse(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	if pkg.URL != "example.com/pkg" || pkg.Name != "pkg" || pkg.Version != "v1.2.3" {
+		t.Errorf("expected example.com/pkg@v1.2.3 named pkg, got %q@%q named %q", pkg.URL, pkg.Version, pkg.Name)
+	}
+	if len(pkg.Overview) == 0 || pkg.Overview[0] != doc.Paragraph("Package pkg does things.") {
+		t.Errorf("unexpected overview: %#v", pkg.Overview)
+	}
+	rich := doc.RichText{
+		doc.Plain("See "),
+		doc.DocLink{Label: "http.Server", ImportPath: "net/http", Name: "Server", URL: "https://pkg.go.dev/net/http#Server"},
+		doc.Plain(", "),
+		doc.DocLink{Label: "T.Run", Recv: "T", Name: "Run", URL: "https://pkg.go.dev/example.com/pkg@v1.2.3#T.Run"},
+		doc.Plain(" and "),
+		doc.Code("Do"),
+		doc.Plain(", or "),
+		doc.Link{Label: "the Go website", URL: "https://go.dev/"},
+		doc.Plain(". Also "),
+		doc.DocLink{Label: "http.Client", ImportPath: "net/http", Name: "Client", URL: "https://pkg.go.dev/net/http#Client"},
+		doc.Plain(", "),
+		doc.Link{Label: "Usage", URL: "https://pkg.go.dev/example.com/pkg@v1.2.3#hdr-Usage"},
+		doc.Plain(" and "),
+		doc.Link{Label: "about", URL: "https://pkg.go.dev/about"},
+		doc.Plain("."),
+	}
+	if len(pkg.Overview) < 3 || !reflect.DeepEqual(pkg.Overview[1], rich) {
+		t.Errorf("unexpected rich text: %#v", pkg.Overview)
+	}
+	list := doc.List{Ordered: true, Items: []doc.ListItem{
+		{Number: "1", Content: doc.Comment{doc.Paragraph("First.")}},
+		{Number: "2", Content: doc.Comment{doc.Paragraph("Second.")}},
+	}}
+	if len(pkg.Overview) < 3 || !reflect.DeepEqual(pkg.Overview[2], list) {
+		t.Errorf("unexpected list: %#v", pkg.Overview)
+	}
+	if _, ok := pkg.ConstantMap["max"]; !ok {
+		t.Errorf("expected constant Max, got %v", pkg.ConstantMap)
+	}
+	if _, ok := pkg.VariableMap["errbad"]; !ok {
+		t.Errorf("expected variable ErrBad, got %v", pkg.VariableMap)
+	}
+	if _, ok := pkg.Functions["do"]; !ok {
+		t.Errorf("expected function Do, got %v", pkg.Functions)
+	}
+	typ, ok := pkg.Types["t"]
+	if !ok {
+		t.Fatalf("expected type T, got %v", pkg.Types)
+	}
+	if _, ok := typ.TypeFunctions["newt"]; !ok {
+		t.Errorf("expected type function NewT, got %v", typ.TypeFunctions)
+	}
+	if m, ok := typ.Methods["run"]; !ok || m.For != "T" {
+		t.Errorf("expected method Run on T, got %v", typ.Methods)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	fields := []doc.Field{
+		{Name: "Name", Type: "string", Tag: `json:"name"`, Comment: doc.Comment{doc.Paragraph("Name is the name.")}},
+		{Name: "Buffer", Type: "*bytes.Buffer", Embedded: true},
+		{Name: "X", Type: "int", Comment: doc.Comment{doc.Paragraph("X and Y are coordinates.")}},
+		{Name: "Y", Type: "int", Comment: doc.Comment{doc.Paragraph("X and Y are coordinates.")}},
+	}
+	if got := pkg.Types["t"].Fields; !reflect.DeepEqual(got, fields) {
+		t.Errorf("unexpected fields: %#v", got)
+	}
+
+	methods := []doc.InterfaceMethod{
+		{Name: "Run", Signature: "Run(ctx context.Context) error", Comment: doc.Comment{doc.Paragraph("Run runs until ctx is done.")}},
+		{Name: "Stringer", Signature: "fmt.Stringer", Embedded: true},
+	}
+	if got := pkg.Types["runner"].InterfaceMethods; !reflect.DeepEqual(got, methods) {
+		t.Errorf("unexpected interface methods: %#v", got)
+	}
+
+	if kind := pkg.Types["t"].Type; kind != doc.StructType {
+		t.Errorf("expected T to be a struct, got %q", kind)
+	}
+	if types := pkg.TypesOfKind(doc.InterfaceType); len(types) != 1 || types[0].Name != "Runner" {
+		t.Errorf("expected Runner to be the only interface, got %v", types)
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	want := &doc.Signature{Results: []doc.Param{{Type: "error"}}}
+	if got := pkg.Functions["do"].Parsed; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected signature for Do: %#v", got)
+	}
+	want = &doc.Signature{Receiver: &doc.Receiver{Name: "t", Type: "T"}}
+	if got := pkg.Types["t"].Methods["run"].Parsed; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected signature for T.Run: %#v", got)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	pkg, err := parseFile(t, "package.html")
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+	if len(pkg.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", pkg.Diagnostics)
+	}
+
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(`<div class="UnitDoc"><section class="Documentation-overview"><h3></h3><h4>Heading</h4><ul><li>item</li></ul></section><div class="Documentation-function"><h4 class="Documentation-functionHeader"><a>Do</a></h4></div></div>`))
+	if err != nil {
+		t.Fatalf("could not parse html: %v", err)
+	}
+	pkg, err = pkgsite.Parser.Parse(document, false, false)
+	if err != nil {
+		t.Fatalf("could not parse package: %v", err)
+	}
+
+	kinds := map[doc.DiagnosticKind]int{}
+	for _, d := range pkg.Diagnostics {
+		kinds[d.Kind]++
+	}
+	for _, kind := range []doc.DiagnosticKind{doc.MissingSection, doc.EmptySignature, doc.UnrecognizedNode} {
+		if kinds[kind] == 0 {
+			t.Errorf("expected %v diagnostic, got %v", kind, pkg.Diagnostics)
+		}
+	}
+}
+
+func TestParseNotFound(t *testing.T) {
+	if _, err := parseFile(t, "notfound.html"); !errors.Is(err, doc.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, html := range []string{
+		`<nav class="go-Breadcrumb"><ol><li><a>pkg</a></li></ol></nav>`,
+		`<div class="UnitDoc"><section class="Documentation-overview"><h3></h3><h4 id="hdr-"></h4></section></div>`,
+	} {
+		document, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		if err != nil {
+			t.Fatalf("could not parse html: %v", err)
+		}
+
+		if _, err := pkgsite.Parser.Parse(document, false, false); err != nil {
+			t.Errorf("expected malformed page %q to parse, got %v", html, err)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, name := range []string{"package.html", "notfound.html"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			f.Fatalf("could not read fixture: %v", err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte(`<nav class="go-Breadcrumb"><ol><li><a>pkg</a></li></ol></nav>`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		document, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		_, err = pkgsite.Parser.Parse(document, false, true)
+		var panicErr *doc.PanicError
+		if errors.As(err, &panicErr) {
+			t.Fatalf("parser panicked: %v\n%s", panicErr.Value, panicErr.Stack)
+		}
+	})
+}
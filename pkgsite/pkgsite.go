@@ -1,6 +1,9 @@
 package pkgsite
 
 import (
+	"fmt"
+	"runtime/debug"
+
 	"github.com/PuerkitoBio/goquery"
 	"github.com/hhhapz/doc"
 )
@@ -10,12 +13,12 @@ const (
 	base = "https://pkg.go.dev/"
 )
 
-// pkgsiteParser implements doc.Parser.
+// pkgsiteParser implements doc.VersionedParser.
 type pkgsiteParser struct{}
 
 // Parser is an implementation of godoc.Parser that retrieves documentation
 // from https://godocs.io.
-var Parser doc.Parser = pkgsiteParser{}
+var Parser doc.VersionedParser = pkgsiteParser{}
 
 // URL returns a url to the path to see the documentation for the provided
 // module on https://godocs.io/.
@@ -23,10 +26,31 @@ func (pkgsiteParser) URL(module string) string {
 	return base + module
 }
 
-func (p pkgsiteParser) Parse(document *goquery.Document, useCase, dupeTypeFuncs bool) (doc.Package, error) {
-	// special case not found case for godocs
+// VersionURL returns a url to the path to see the documentation for the
+// provided module at a specific version on https://pkg.go.dev/. Besides
+// semantic versions, pkg.go.dev also resolves branch names such as master to
+// their pseudo version.
+func (pkgsiteParser) VersionURL(path, version string) string {
+	return base + path + "@" + version
+}
+
+// Parse parses the documentation page of a package on pkg.go.dev. Unexpected
+// markup that would cause a panic results in a *ParseError wrapping a
+// *doc.PanicError instead.
+func (p pkgsiteParser) Parse(document *goquery.Document, useCase, dupeTypeFuncs bool) (pkg doc.Package, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pkg, err = doc.Package{}, &ParseError{
+				Parser:  "pkgsite",
+				Message: "unexpected markup",
+				Err:     &doc.PanicError{Value: r, Stack: debug.Stack()},
+			}
+		}
+	}()
+
+	// special case not found case for pkgsite
 	if document.Find("h3.Error-message").Text() == "404 Not Found" {
-		return doc.Package{}, doc.InvalidStatusError(404)
+		return doc.Package{}, fmt.Errorf("%w: not found page on pkg.go.dev", doc.ErrNotFound)
 	}
 
 	s, err := newState(document, useCase)
@@ -34,15 +58,17 @@ func (p pkgsiteParser) Parse(document *goquery.Document, useCase, dupeTypeFuncs
 		return doc.Package{}, err
 	}
 
-	consts := document.Find("section.Documentation-constants")
+	consts := s.section("section.Documentation-constants")
 	s.variables(consts.Children(), true, s.pkg.ConstantMap)
 
-	vars := document.Find("section.Documentation-variables")
+	vars := s.section("section.Documentation-variables")
 	s.variables(vars.Children(), false, s.pkg.VariableMap)
 
+	s.section("section.Documentation-functions")
 	funcs := document.Find(".Documentation-function")
 	s.functions(funcs)
 
+	s.section("section.Documentation-types")
 	types := document.Find(".Documentation-type")
 	types.Each(func(i int, sel *goquery.Selection) {
 		t, _ := s.typ(sel)
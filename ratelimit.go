@@ -0,0 +1,101 @@
+package doc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of requests made to each host using a token
+// bucket per host. A RateLimiter may be shared by any number of searchers, by
+// passing the same RateLimiter to WithRateLimiter.
+type RateLimiter struct {
+	limit float64
+	burst int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is the token bucket of a single host.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter that allows limit requests per second to
+// each host, with bursts of up to burst requests. A burst below 1 is treated
+// as 1.
+func NewRateLimiter(limit float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		burst:   max(burst, 1),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// WithRateLimiter makes the searcher wait for l before each request, including
+// retries. Waiting stops early with the error of the context if it is done, or
+// if its deadline is too soon to make the request.
+func WithRateLimiter(l *RateLimiter) SearchOption {
+	return func(s *httpSearcher) {
+		s.limiter = l
+	}
+}
+
+// Wait blocks until a request to host is allowed, or ctx is done. If ctx has a
+// deadline before the request would be allowed, Wait returns
+// context.DeadlineExceeded immediately.
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil || l.limit <= 0 {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	delay := l.reserve(host, now)
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		l.cancel(host)
+		return context.DeadlineExceeded
+	}
+	if err := sleep(ctx, delay); err != nil {
+		l.cancel(host)
+		return err
+	}
+	return nil
+}
+
+// reserve takes a token from the bucket of host, and returns how long to wait
+// until the token is available.
+func (l *RateLimiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[host] = b
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(float64(l.burst), b.tokens+elapsed.Seconds()*l.limit)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.limit * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (l *RateLimiter) cancel(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[host]; ok {
+		b.tokens = min(float64(l.burst), b.tokens+1)
+	}
+}
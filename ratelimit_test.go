@@ -0,0 +1,76 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+	now := time.Now()
+
+	for i := range 2 {
+		if d := l.reserve("a", now); d != 0 {
+			t.Errorf("request %d: expected no delay within burst, got %v", i, d)
+		}
+	}
+	if d := l.reserve("a", now); d != 100*time.Millisecond {
+		t.Errorf("expected delay of 100ms, got %v", d)
+	}
+	if d := l.reserve("b", now); d != 0 {
+		t.Errorf("expected hosts to be limited separately, got delay %v", d)
+	}
+	if d := l.reserve("a", now.Add(time.Second)); d != 0 {
+		t.Errorf("expected bucket to refill, got delay %v", d)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	ctx := context.Background()
+	if err := l.Wait(ctx, "a"); err != nil {
+		t.Fatalf("could not wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := l.Wait(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Errorf("expected wait to fail immediately, took %v", d)
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, "<html><head><title>pkg</title></head></html>")
+	}))
+	defer srv.Close()
+
+	l := NewRateLimiter(1, 1)
+	a := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithRateLimiter(l))
+	b := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithRateLimiter(l))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := a.Search(ctx, "pkg"); err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if _, err := b.Search(ctx, "pkg"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected second searcher to be limited, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
@@ -0,0 +1,165 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// AmbiguousPackageError is returned when a short package name, such as
+// "template", matches more than one package.
+type AmbiguousPackageError struct {
+	Name       string
+	Candidates []string
+}
+
+// Error satisfies the error interface.
+func (err *AmbiguousPackageError) Error() string {
+	return fmt.Sprintf("ambiguous package %q, could be: %s", err.Name, strings.Join(err.Candidates, ", "))
+}
+
+// Resolver resolves arguments in the style of the go doc command to a package
+// and optionally a symbol within it.
+type Resolver struct {
+	searcher Searcher
+	expand   func(name string) []string
+}
+
+// ResolveOption configures a Resolver.
+type ResolveOption func(r *Resolver)
+
+// NewResolver creates a Resolver that fetches packages with s.
+func NewResolver(s Searcher, opts ...ResolveOption) *Resolver {
+	r := &Resolver{
+		searcher: s,
+		expand:   ExpandShortName,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithShortNames overrides how short package names, such as "http", are
+// expanded to full import paths. By default, ExpandShortName is used.
+func WithShortNames(expand func(name string) []string) ResolveOption {
+	return func(r *Resolver) {
+		r.expand = expand
+	}
+}
+
+// Resolve finds the package and symbol described by args, following the rules
+// of the go doc command. The following forms are accepted:
+//
+//	<pkg>
+//	<pkg>.<sym>[.<methodOrTypeFunc>]
+//	<pkg> <sym>[.<methodOrTypeFunc>]
+//
+// The package may be a full import path such as net/http, or the last element
+// of a standard library import path such as http. If a short name matches more
+// than one package, an *AmbiguousPackageError is returned.
+//
+// As with go doc, a symbol containing an upper case letter must match exactly,
+// while a lower case symbol matches regardless of case. If no symbol was
+// requested, the returned symbol is nil.
+//
+// Versions, such as golang.org/x/net/html@v0.25.0, are only supported in the
+// two argument form, or when no symbol is requested.
+func (r *Resolver) Resolve(ctx context.Context, args ...string) (Package, *Symbol, error) {
+	var splits [][2]string
+	switch len(args) {
+	case 1:
+		splits = splitArg(args[0])
+	case 2:
+		splits = [][2]string{{args[0], args[1]}}
+	default:
+		return Package{}, nil, fmt.Errorf("expected 1 or 2 arguments, got %d", len(args))
+	}
+
+	var lastErr error
+	for _, split := range splits {
+		pkg, sym, err := r.resolve(ctx, split[0], split[1])
+		switch {
+		case err == nil:
+			return pkg, sym, nil
+		case errors.Is(err, ErrSymbolNotFound), errors.Is(err, ErrNotFound):
+			lastErr = err
+			continue
+		default:
+			return Package{}, nil, err
+		}
+	}
+	return Package{}, nil, fmt.Errorf("could not resolve %q: %w", strings.Join(args, " "), lastErr)
+}
+
+func (r *Resolver) resolve(ctx context.Context, name, query string) (Package, *Symbol, error) {
+	path, err := r.packagePath(name)
+	if err != nil {
+		return Package{}, nil, err
+	}
+
+	pkg, err := r.searcher.Search(ctx, path)
+	if err != nil {
+		return Package{}, nil, err
+	}
+	if query == "" {
+		return pkg, nil, nil
+	}
+
+	mode := MatchFold
+	if strings.ContainsFunc(query, unicode.IsUpper) {
+		mode = MatchExact
+	}
+	sym, err := pkg.Lookup(query, mode)
+	if err != nil {
+		return Package{}, nil, err
+	}
+	return pkg, &sym, nil
+}
+
+// packagePath expands name into a full import path if it is a short name.
+func (r *Resolver) packagePath(name string) (string, error) {
+	path, version := SplitVersion(name)
+	if strings.ContainsAny(path, "./") || r.expand == nil {
+		return name, nil
+	}
+
+	candidates := r.expand(path)
+	for _, c := range candidates {
+		// an exact standard library path, such as bytes, is always preferred.
+		if c == path {
+			return name, nil
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return name, nil
+	case 1:
+		return JoinVersion(candidates[0], version), nil
+	}
+	return "", &AmbiguousPackageError{Name: path, Candidates: candidates}
+}
+
+// splitArg returns the possible package and symbol pairs of a single argument,
+// in the order that they should be tried.
+func splitArg(arg string) [][2]string {
+	var splits [][2]string
+	slash := strings.LastIndex(arg, "/")
+	// a full import path may itself contain a dot after the last slash, such
+	// as gopkg.in/yaml.v3, so it is tried as a package first.
+	if slash >= 0 || !strings.Contains(arg, ".") || strings.Contains(arg, "@") {
+		splits = append(splits, [2]string{arg, ""})
+	}
+	if strings.Contains(arg, "@") {
+		return splits
+	}
+
+	for i := slash + 1; i < len(arg); i++ {
+		if arg[i] == '.' {
+			splits = append(splits, [2]string{arg[:i], arg[i+1:]})
+		}
+	}
+	return splits
+}
@@ -0,0 +1,84 @@
+package doc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hhhapz/doc"
+)
+
+type mapSearcher map[string]doc.Package
+
+func (m mapSearcher) Search(_ context.Context, module string) (doc.Package, error) {
+	pkg, ok := m[module]
+	if !ok {
+		return doc.Package{}, doc.InvalidStatusError(404)
+	}
+	return pkg, nil
+}
+
+func TestResolve(t *testing.T) {
+	s := mapSearcher{
+		"net/http": {Name: "http", Types: map[string]doc.Type{
+			"client": {Name: "Client", Methods: map[string]doc.Method{
+				"do": {For: "Client", Function: doc.Function{Name: "Do"}},
+			}},
+		}},
+		"encoding/json": {Name: "json", Functions: map[string]doc.Function{
+			"marshal": {Name: "Marshal"},
+		}},
+		"text/template": {Name: "template"},
+		"html/template": {Name: "template"},
+	}
+	short := func(name string) []string {
+		switch name {
+		case "http":
+			return []string{"net/http"}
+		case "json":
+			return []string{"encoding/json"}
+		case "template":
+			return []string{"html/template", "text/template"}
+		}
+		return nil
+	}
+	r := doc.NewResolver(s, doc.WithShortNames(short))
+	ctx := context.Background()
+
+	tests := []struct {
+		args []string
+		pkg  string
+		sym  string
+	}{
+		{[]string{"http"}, "http", ""},
+		{[]string{"net/http"}, "http", ""},
+		{[]string{"http.Client.Do"}, "http", "Client.Do"},
+		{[]string{"net/http", "Client"}, "http", "Client"},
+		{[]string{"net/http.client.do"}, "http", "Client.Do"},
+		{[]string{"json.Marshal"}, "json", "Marshal"},
+	}
+	for _, tt := range tests {
+		pkg, sym, err := r.Resolve(ctx, tt.args...)
+		if err != nil {
+			t.Errorf("Resolve(%q): unexpected error: %v", tt.args, err)
+			continue
+		}
+		if pkg.Name != tt.pkg {
+			t.Errorf("Resolve(%q): got package %q, want %q", tt.args, pkg.Name, tt.pkg)
+		}
+		switch {
+		case tt.sym == "" && sym != nil:
+			t.Errorf("Resolve(%q): got symbol %q, want none", tt.args, sym.FullName())
+		case tt.sym != "" && (sym == nil || sym.FullName() != tt.sym):
+			t.Errorf("Resolve(%q): got symbol %v, want %q", tt.args, sym, tt.sym)
+		}
+	}
+
+	var ambiguous *doc.AmbiguousPackageError
+	if _, _, err := r.Resolve(ctx, "template.New"); !errors.As(err, &ambiguous) {
+		t.Errorf("expected AmbiguousPackageError, got %v", err)
+	}
+	if _, _, err := r.Resolve(ctx, "json.Unmarshal"); !errors.Is(err, doc.ErrSymbolNotFound) {
+		t.Errorf("expected ErrSymbolNotFound, got %v", err)
+	}
+}
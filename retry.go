@@ -0,0 +1,141 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how a searcher retries failed requests. The zero
+// value does not retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of requests made for a search,
+	// including the first one.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It is doubled for every
+	// following retry, up to MaxBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff limits the delay between retries. If zero, the delay is not
+	// limited. Requests whose Retry-After header asks for a longer delay are
+	// not retried.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the delay, between 0 and 1, that is
+	// randomized to avoid retrying in lockstep with other clients.
+	Jitter float64
+	// Retryable reports whether a request that failed with err is retried.
+	// Unsuccessful statuses are reported as an InvalidStatusError. If nil,
+	// DefaultRetryable is used.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy retries up to two times, waiting 500ms and then 1s,
+// with half of each delay randomized.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.5,
+}
+
+// DefaultRetryable retries requests that failed with ErrRateLimited, with a
+// 502, 503 or 504 status, or before a response was received, unless the
+// context is done.
+func DefaultRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	var status InvalidStatusError
+	if errors.As(err, &status) {
+		switch status {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// WithRetry makes the searcher retry failed requests according to policy.
+//
+// If the response has a Retry-After header, the searcher waits as long as the
+// header requests instead. A request is not retried if the delay would exceed
+// the policy's MaxBackoff or the deadline of the context.
+func WithRetry(policy RetryPolicy) SearchOption {
+	return func(s *httpSearcher) {
+		s.retry = policy
+	}
+}
+
+// delay returns how long to wait before retrying a request that failed with
+// err on the given attempt, starting at 1. If the request should not be
+// retried, false is returned.
+func (p RetryPolicy) delay(attempt int, err error, retryAfter time.Duration) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	if !retryable(err) {
+		return 0, false
+	}
+	if retryAfter > 0 {
+		if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+			return 0, false
+		}
+		return retryAfter, true
+	}
+
+	d := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 && d > 0 {
+		jitter := time.Duration(float64(d) * min(p.Jitter, 1))
+		d = d - jitter + rand.N(jitter+1)
+	}
+	return d, true
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date. Zero is returned if the header is not
+// set or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,167 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	var requests, failures atomic.Int64
+	failures.Store(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "<html><head><title>pkg</title></head></html>")
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+	}))
+	pkg, err := s.Search(context.Background(), "pkg")
+	if err != nil {
+		t.Fatalf("could not search: %v", err)
+	}
+	if pkg.Name != "pkg" {
+		t.Errorf("expected package pkg, got %q", pkg.Name)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+
+	requests.Store(0)
+	failures.Store(3)
+	_, err = s.Search(context.Background(), "pkg")
+	if !errors.Is(err, InvalidStatusError(503)) {
+		t.Errorf("expected unavailable error, got %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithRetry(DefaultRetryPolicy))
+	_, err := s.Search(context.Background(), "pkg")
+	if !errors.Is(err, InvalidStatusError(404)) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestRetryAfterDeadline(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithRetry(DefaultRetryPolicy))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := s.Search(ctx, "pkg")
+	if !errors.Is(err, InvalidStatusError(429)) {
+		t.Errorf("expected rate limited error, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected search to give up before the deadline, took %v", d)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-1", 0},
+		{"Mon, 01 Jan 2024 00:00:30 GMT", 30 * time.Second},
+		{"Sun, 31 Dec 2023 00:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second, MaxBackoff: 3 * time.Second}
+	err := InvalidStatusError(503)
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		d, ok := p.delay(attempt+1, err, 0)
+		if !ok || d != want {
+			t.Errorf("attempt %d: expected delay %v, got %v (%v)", attempt+1, want, d, ok)
+		}
+	}
+	if _, ok := p.delay(5, err, 0); ok {
+		t.Errorf("expected no retry after the last attempt")
+	}
+}
+
+func TestRetryAfterMaxBackoff(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), WithRetry(DefaultRetryPolicy))
+	start := time.Now()
+	_, err := s.Search(context.Background(), "pkg")
+	if !errors.Is(err, InvalidStatusError(429)) {
+		t.Errorf("expected rate limited error, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected search to give up instead of waiting a day, took %v", d)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	if d, ok := p.delay(1, ErrRateLimited, 5*time.Second); !ok || d != 5*time.Second {
+		t.Errorf("expected the Retry-After delay of 5s, got %v (%v)", d, ok)
+	}
+	if _, ok := p.delay(1, ErrRateLimited, 24*time.Hour); ok {
+		t.Errorf("expected no retry when Retry-After exceeds the maximum backoff")
+	}
+
+	p.MaxBackoff = 0
+	if d, ok := p.delay(1, ErrRateLimited, 24*time.Hour); !ok || d != 24*time.Hour {
+		t.Errorf("expected an unlimited delay without a maximum backoff, got %v (%v)", d, ok)
+	}
+}
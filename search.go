@@ -3,15 +3,25 @@ package doc
 import (
 	"context"
 	"net/http"
-	"sync"
+	"time"
 )
 
 type Searcher interface {
-	// Search will find a package with the module name.
+	// Search will find a package with the module name. The module may be
+	// suffixed with @version, such as "golang.org/x/net@v0.25.0", to find a
+	// specific version instead of the latest one. The resolved version is
+	// available through Package.Version.
 	Search(ctx context.Context, module string) (Package, error)
 }
 
+// NewSearcher creates a searcher that fetches and parses documentation pages
+// with parser. Cache options, such as WithMaxAge, have no effect; use
+// NewCachedSearcher or the Cache middleware to cache results.
 func NewSearcher(parser Parser, opts ...SearchOption) Searcher {
+	return newHTTPSearcher(parser, opts...)
+}
+
+func newHTTPSearcher(parser Parser, opts ...SearchOption) *httpSearcher {
 	s := &httpSearcher{
 		client:   http.DefaultClient,
 		parser:   parser,
@@ -28,15 +38,24 @@ type CachedSearcher interface {
 	Searcher
 	// WithCache gives access to modify and update the contents of the internal cache.
 	WithCache(func(cache map[string]*CachedPackage))
+	// Stats returns the hit, miss and eviction counters of the cache, and its
+	// current size.
+	Stats() CacheStats
+	// Entries lists the keys of the cached packages with their timestamps.
+	Entries() []StoreEntry
+	// Close stops any background work of the cache.
+	Close() error
 }
 
+// NewCachedSearcher creates a searcher that caches results in memory, or in the
+// store set with WithStore. Besides the options of NewSearcher, the cache can
+// be configured with the cache options WithStore, WithMaxEntries, WithMaxAge,
+// WithStaleWhileRevalidate, WithNotFoundTTL and WithJanitor. These options are
+// silently ignored when passed to NewSearcher.
+//
+// It is equivalent to wrapping NewSearcher with the Cache middleware.
 func NewCachedSearcher(parser Parser, opts ...SearchOption) CachedSearcher {
-	s := NewSearcher(parser, opts...)
-	return &cachedSearcher{
-		Searcher: s,
-		mu:       sync.RWMutex{},
-		cache:    map[string]*CachedPackage{},
-	}
+	return Cache(opts...)(NewSearcher(parser, opts...)).(CachedSearcher)
 }
 
 type SearchOption = func(s *httpSearcher)
@@ -64,3 +83,67 @@ func WithDuplicateTypeFuncs() SearchOption {
 		s.duplicateTypeFuncs = true
 	}
 }
+
+// ExpandShortNames makes the searcher expand short names of standard library
+// packages, such as http, into their full import path using ExpandShortName.
+// If a short name matches more than one package, an *AmbiguousPackageError is
+// returned with the candidates.
+func ExpandShortNames() SearchOption {
+	return func(s *httpSearcher) {
+		s.expandShortNames = true
+	}
+}
+
+// WithStore sets the Store used by a cached searcher, such as one created with
+// NewFileStore. By default, packages are only kept in memory.
+func WithStore(store Store) SearchOption {
+	return func(s *httpSearcher) {
+		s.cache.store = store
+	}
+}
+
+// WithMaxEntries limits the number of packages kept by a cached searcher. When
+// the limit is exceeded, the least recently used package is evicted.
+func WithMaxEntries(n int) SearchOption {
+	return func(s *httpSearcher) {
+		s.cache.maxEntries = n
+	}
+}
+
+// WithMaxAge sets how long a package is cached for after it was fetched. Older
+// packages are fetched again the next time they are searched for.
+func WithMaxAge(d time.Duration) SearchOption {
+	return func(s *httpSearcher) {
+		s.cache.maxAge = d
+	}
+}
+
+// WithStaleWhileRevalidate makes a cached searcher return expired packages
+// immediately, while fetching the package again in the background. Expired
+// packages removed by a janitor are fetched as usual.
+//
+// If onRefresh is not nil, it is called with the requested module when the
+// refreshed package differs from the stale one that was returned.
+func WithStaleWhileRevalidate(onRefresh func(module string, stale, fresh Package)) SearchOption {
+	return func(s *httpSearcher) {
+		s.cache.staleWhileRevalidate = true
+		s.cache.onRefresh = onRefresh
+	}
+}
+
+// WithNotFoundTTL makes a cached searcher remember packages that could not be
+// found for d, returning the same error without searching again until then.
+func WithNotFoundTTL(d time.Duration) SearchOption {
+	return func(s *httpSearcher) {
+		s.cache.notFoundTTL = d
+	}
+}
+
+// WithJanitor starts a goroutine that removes expired packages from a cached
+// searcher every interval, until ctx is done or the searcher is closed.
+func WithJanitor(ctx context.Context, interval time.Duration) SearchOption {
+	return func(s *httpSearcher) {
+		s.cache.janitorCtx = ctx
+		s.cache.janitorInterval = interval
+	}
+}
@@ -0,0 +1,101 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pathServer serves every module except those in missing, titled with the
+// path of the request, and records the requested paths.
+func pathServer(missing ...string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		mu.Lock()
+		paths = append(paths, path)
+		mu.Unlock()
+
+		if slices.Contains(missing, path) {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<html><head><title>%s</title></head></html>", path)
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(paths)
+	}
+}
+
+func TestExpandShortNames(t *testing.T) {
+	srv, paths := pathServer()
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), ExpandShortNames())
+	for _, module := range []string{"http", "net/http", "pkix", "github.com/foo/http"} {
+		if _, err := s.Search(context.Background(), module); err != nil {
+			t.Fatalf("could not search %q: %v", module, err)
+		}
+	}
+	want := []string{"net/http", "net/http", "crypto/x509/pkix", "github.com/foo/http"}
+	if got := paths(); !slices.Equal(got, want) {
+		t.Errorf("expected requests for %q, got %q", want, got)
+	}
+
+	s = NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()))
+	if pkg, err := s.Search(context.Background(), "http"); err != nil || pkg.Name != "http" {
+		t.Errorf("expected short name to not be expanded by default, got %q, %v", pkg.Name, err)
+	}
+}
+
+func TestExpandShortNamesAmbiguous(t *testing.T) {
+	srv, paths := pathServer()
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()), ExpandShortNames())
+	_, err := s.Search(context.Background(), "template")
+
+	var ambiguous *AmbiguousPackageError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected ambiguous package error, got %v", err)
+	}
+	if want := []string{"html/template", "text/template"}; ambiguous.Name != "template" || !slices.Equal(ambiguous.Candidates, want) {
+		t.Errorf("expected template to match %q, got %q matching %q", want, ambiguous.Name, ambiguous.Candidates)
+	}
+	if got := paths(); len(got) != 0 {
+		t.Errorf("expected no requests, got %q", got)
+	}
+}
+
+func TestSearchSuggestions(t *testing.T) {
+	srv, _ := pathServer("byts", "github.com/foo/missing")
+	defer srv.Close()
+
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()))
+	_, err := s.Search(context.Background(), "byts")
+
+	var suggestion *SuggestionError
+	if !errors.As(err, &suggestion) {
+		t.Fatalf("expected suggestion error, got %v", err)
+	}
+	if suggestion.Module != "byts" || !slices.Contains(suggestion.Suggestions, "bytes") {
+		t.Errorf("expected bytes to be suggested for byts, got %q for %q", suggestion.Suggestions, suggestion.Module)
+	}
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, InvalidStatusError(404)) {
+		t.Errorf("expected suggestion error to match the not found error, got %v", err)
+	}
+
+	_, err = s.Search(context.Background(), "github.com/foo/missing")
+	if !errors.Is(err, ErrNotFound) || errors.As(err, &suggestion) {
+		t.Errorf("expected not found error without suggestions, got %v", err)
+	}
+}
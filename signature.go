@@ -0,0 +1,121 @@
+package doc
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// Signature is the parsed signature of a function or method.
+type Signature struct {
+	// Receiver is the receiver of a method, and nil for functions.
+	Receiver *Receiver `json:"receiver"`
+	// TypeParams are the type parameters of a generic function. The Type of
+	// each parameter is its constraint.
+	TypeParams []Param `json:"type_params"`
+	Params     []Param `json:"params"`
+	Results    []Param `json:"results"`
+	// Variadic reports whether the last parameter is variadic. Its Type
+	// starts with "...".
+	Variadic bool `json:"variadic"`
+}
+
+// Receiver is the receiver of a method.
+type Receiver struct {
+	// Name is the name of the receiver, and empty if it is unnamed.
+	Name string `json:"name"`
+	// Type is the type of the receiver without the pointer, such as "T" or
+	// "List[T]".
+	Type    string `json:"type"`
+	Pointer bool   `json:"pointer"`
+}
+
+// Param is a parameter, result or type parameter of a function.
+type Param struct {
+	// Name is the name of the parameter, and empty if it is unnamed.
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ParseSignature parses the signature of a function or method, as found in
+// Function.Signature, for use by parsers. An error is returned if the
+// signature is not a valid function declaration.
+func ParseSignature(signature string) (*Signature, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", "package p\n"+signature, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var decl *ast.FuncDecl
+	for _, d := range file.Decls {
+		if d, ok := d.(*ast.FuncDecl); ok {
+			decl = d
+			break
+		}
+	}
+	if decl == nil {
+		return nil, errors.New("not a function declaration")
+	}
+
+	sig := &Signature{
+		TypeParams: params(fset, decl.Type.TypeParams),
+		Params:     params(fset, decl.Type.Params),
+		Results:    params(fset, decl.Type.Results),
+	}
+	if list := decl.Type.Params.List; len(list) > 0 {
+		_, sig.Variadic = list[len(list)-1].Type.(*ast.Ellipsis)
+	}
+
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		field := decl.Recv.List[0]
+		recv := &Receiver{}
+		if len(field.Names) > 0 {
+			recv.Name = field.Names[0].Name
+		}
+		typ := field.Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			recv.Pointer = true
+			typ = star.X
+		}
+		recv.Type = printExpr(fset, typ)
+		sig.Receiver = recv
+	}
+	return sig, nil
+}
+
+// ParseFunction sets the parsed signature of f using ParseSignature, for use by
+// parsers. If the signature is not a valid function declaration, an
+// InvalidDeclaration diagnostic for selector is added to the package instead.
+func (p *Package) ParseFunction(f *Function, selector string) {
+	if f.Signature == "" {
+		return
+	}
+	sig, err := ParseSignature(f.Signature)
+	if err != nil {
+		p.Diagnose(InvalidDeclaration, selector, err.Error())
+		return
+	}
+	f.Parsed = sig
+}
+
+// params flattens a field list into one Param per name.
+func params(fset *token.FileSet, fields *ast.FieldList) []Param {
+	if fields == nil {
+		return nil
+	}
+
+	var result []Param
+	for _, f := range fields.List {
+		typ := printExpr(fset, f.Type)
+		if len(f.Names) == 0 {
+			result = append(result, Param{Type: typ})
+			continue
+		}
+		for _, name := range f.Names {
+			result = append(result, Param{Name: name.Name, Type: typ})
+		}
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package doc_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hhhapz/doc"
+)
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      doc.Signature
+	}{
+		{"func Do()", doc.Signature{}},
+		{
+			"func Printf(format string, a ...any) (n int, err error)",
+			doc.Signature{
+				Params:   []doc.Param{{Name: "format", Type: "string"}, {Name: "a", Type: "...any"}},
+				Results:  []doc.Param{{Name: "n", Type: "int"}, {Name: "err", Type: "error"}},
+				Variadic: true,
+			},
+		},
+		{
+			"func Map[K comparable, V any, M ~map[K]V](m M, f func(K, V) bool) []K",
+			doc.Signature{
+				TypeParams: []doc.Param{{Name: "K", Type: "comparable"}, {Name: "V", Type: "any"}, {Name: "M", Type: "~map[K]V"}},
+				Params:     []doc.Param{{Name: "m", Type: "M"}, {Name: "f", Type: "func(K, V) bool"}},
+				Results:    []doc.Param{{Type: "[]K"}},
+			},
+		},
+		{
+			"func (l *List[T]) Push(x, y T) error",
+			doc.Signature{
+				Receiver: &doc.Receiver{Name: "l", Type: "List[T]", Pointer: true},
+				Params:   []doc.Param{{Name: "x", Type: "T"}, {Name: "y", Type: "T"}},
+				Results:  []doc.Param{{Type: "error"}},
+			},
+		},
+		{
+			"func (Buffer) Len() int",
+			doc.Signature{
+				Receiver: &doc.Receiver{Type: "Buffer"},
+				Results:  []doc.Param{{Type: "int"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		sig, err := doc.ParseSignature(tt.signature)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", tt.signature, err)
+			continue
+		}
+		if !reflect.DeepEqual(*sig, tt.want) {
+			t.Errorf("unexpected signature for %q: %#v", tt.signature, *sig)
+		}
+	}
+}
+
+func TestParseSignatureInvalid(t *testing.T) {
+	for _, signature := range []string{"func Do(", "type T int", ""} {
+		if _, err := doc.ParseSignature(signature); err == nil {
+			t.Errorf("expected error for %q", signature)
+		}
+	}
+}
+
+func TestPackageParseFunction(t *testing.T) {
+	var pkg doc.Package
+	f := doc.Function{Name: "Do", Signature: "func Do(n int) error"}
+	pkg.ParseFunction(&f, "#Do")
+	if f.Parsed == nil || len(f.Parsed.Params) != 1 || len(pkg.Diagnostics) != 0 {
+		t.Errorf("unexpected signature %+v with diagnostics %v", f.Parsed, pkg.Diagnostics)
+	}
+
+	f = doc.Function{Name: "Do", Signature: "func Do("}
+	pkg.ParseFunction(&f, "#Do")
+	if f.Parsed != nil {
+		t.Errorf("expected invalid signature to be left unparsed, got %+v", f.Parsed)
+	}
+	if len(pkg.Diagnostics) != 1 || pkg.Diagnostics[0].Kind != doc.InvalidDeclaration || pkg.Diagnostics[0].Selector != "#Do" {
+		t.Errorf("expected an invalid declaration diagnostic for #Do, got %v", pkg.Diagnostics)
+	}
+}
@@ -0,0 +1,177 @@
+package doc
+
+import (
+	"sort"
+	"unsafe"
+)
+
+// CacheStats describes the effectiveness and size of a cached searcher.
+type CacheStats struct {
+	// Hits is the number of searches that were answered from the cache.
+	Hits uint64
+	// Misses is the number of searches that were not in the cache, or had
+	// expired.
+	Misses uint64
+	// Evictions is the number of packages removed from the cache because they
+	// expired, or the maximum number of entries was exceeded.
+	Evictions uint64
+	// InFlight is the number of searches currently being fetched.
+	InFlight int64
+	// Entries is the number of packages in the cache, one for every key
+	// listed by Entries. The resolved versions of unversioned searches are
+	// aliases of those entries, and are not counted.
+	Entries int
+	// Size is the approximate size of the cached packages in bytes, as
+	// reported by Store.Size. Like Entries, it does not include aliases.
+	Size int64
+}
+
+// Stats returns the current statistics of the cache.
+func (c *cachedSearcher) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		InFlight:  c.inFlight.Load(),
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if entries, err := c.store.Entries(); err == nil {
+		stats.Entries = len(entries)
+	}
+	if size, err := c.store.Size(); err == nil {
+		stats.Size = size
+	}
+	return stats
+}
+
+// Entries lists the keys of all cached packages with their Created and Updated
+// times, sorted by key. Aliases for the resolved versions of unversioned
+// searches are not listed.
+func (c *cachedSearcher) Entries() []StoreEntry {
+	c.mu.RLock()
+	entries, err := c.store.Entries()
+	c.mu.RUnlock()
+	if err != nil {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// size returns the approximate number of bytes used by the package in memory.
+func (p Package) size() int64 {
+	n := int64(unsafe.Sizeof(p))
+	n += int64(len(p.URL) + len(p.Name) + len(p.Version) + len(p.Source))
+	n += p.Overview.size()
+	n += examplesSize(p.Examples)
+
+	for _, v := range p.Constants {
+		n += v.size()
+	}
+	for _, v := range p.Variables {
+		n += v.size()
+	}
+	for k, v := range p.ConstantMap {
+		n += int64(len(k)) + v.size()
+	}
+	for k, v := range p.VariableMap {
+		n += int64(len(k)) + v.size()
+	}
+	for k, f := range p.Functions {
+		n += int64(len(k)) + f.size()
+	}
+	for k, t := range p.Types {
+		n += int64(len(k)) + t.size()
+	}
+	for _, s := range p.Subpackages {
+		n += int64(len(s))
+	}
+	for _, d := range p.Diagnostics {
+		n += int64(unsafe.Sizeof(d)) + int64(len(d.Selector)+len(d.Message))
+	}
+	return n
+}
+
+func (v Variable) size() int64 {
+	return int64(unsafe.Sizeof(v)) + int64(len(v.Name)+len(v.Signature)) + v.Comment.size()
+}
+
+func (f Function) size() int64 {
+	n := int64(unsafe.Sizeof(f)) + int64(len(f.Name)+len(f.Signature))
+	return n + f.Parsed.size() + f.Comment.size() + examplesSize(f.Examples)
+}
+
+func (s *Signature) size() int64 {
+	if s == nil {
+		return 0
+	}
+	n := int64(unsafe.Sizeof(*s))
+	if s.Receiver != nil {
+		n += int64(unsafe.Sizeof(*s.Receiver)) + int64(len(s.Receiver.Name)+len(s.Receiver.Type))
+	}
+	for _, params := range [][]Param{s.TypeParams, s.Params, s.Results} {
+		for _, p := range params {
+			n += int64(unsafe.Sizeof(p)) + int64(len(p.Name)+len(p.Type))
+		}
+	}
+	return n
+}
+
+func (t Type) size() int64 {
+	n := int64(unsafe.Sizeof(t)) + int64(len(t.Name)+len(t.Type)+len(t.Signature))
+	n += t.Comment.size() + examplesSize(t.Examples)
+	for _, f := range t.Fields {
+		n += int64(unsafe.Sizeof(f)+uintptr(len(f.Name)+len(f.Type)+len(f.Tag))) + f.Comment.size()
+	}
+	for _, m := range t.InterfaceMethods {
+		n += int64(unsafe.Sizeof(m)+uintptr(len(m.Name)+len(m.Signature))) + m.Comment.size()
+	}
+	for k, f := range t.TypeFunctions {
+		n += int64(len(k)) + f.size()
+	}
+	for k, m := range t.Methods {
+		n += int64(len(k)+len(m.For)) + m.Function.size()
+	}
+	return n
+}
+
+func (c Comment) size() int64 {
+	var n int64
+	for _, note := range c {
+		switch note := note.(type) {
+		case Comment:
+			n += note.size()
+		case Heading:
+			n += int64(len(note))
+		case Paragraph:
+			n += int64(len(note))
+		case Pre:
+			n += int64(len(note))
+		case RichText:
+			n += Comment(note).size()
+		case List:
+			for _, item := range note.Items {
+				n += int64(unsafe.Sizeof(item)+uintptr(len(item.Number))) + item.Content.size()
+			}
+		default:
+			n += int64(len(note.Text()))
+		}
+		// interface header
+		n += 2 * int64(unsafe.Sizeof(uintptr(0)))
+	}
+	return n
+}
+
+func examplesSize(examples []Example) int64 {
+	var n int64
+	for _, e := range examples {
+		n += int64(unsafe.Sizeof(e)) + int64(len(e.Name)+len(e.Code)+len(e.Output))
+	}
+	return n
+}
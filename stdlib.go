@@ -0,0 +1,156 @@
+package doc
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:generate go run gen_stdlib.go
+
+// stdlibIndex contains the import paths of the standard library, one per line.
+// It is generated from GOROOT by gen_stdlib.go.
+//
+//go:embed stdlib.txt
+var stdlibIndex string
+
+var stdlibPackages = sync.OnceValues(func() ([]string, map[string]bool) {
+	var pkgs []string
+	set := map[string]bool{}
+	for _, line := range strings.Split(stdlibIndex, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pkgs = append(pkgs, line)
+		set[line] = true
+	}
+	return pkgs, set
+})
+
+// StdlibPackages returns the import paths of all importable packages in the
+// standard library, sorted alphabetically.
+func StdlibPackages() []string {
+	pkgs, _ := stdlibPackages()
+	return append([]string(nil), pkgs...)
+}
+
+// IsStdlib reports whether path is the import path of a standard library
+// package.
+func IsStdlib(path string) bool {
+	_, set := stdlibPackages()
+	return set[path]
+}
+
+// ExpandShortName returns the standard library packages that a short package
+// name refers to, such as net/http for http, or both html/template and
+// text/template for template. The name may also contain multiple trailing
+// elements of the import path, such as x509/pkix.
+//
+// If name is itself a standard library import path, only name is returned.
+func ExpandShortName(name string) []string {
+	if IsStdlib(name) {
+		return []string{name}
+	}
+
+	pkgs, _ := stdlibPackages()
+	var matches []string
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg, "/"+name) {
+			matches = append(matches, pkg)
+		}
+	}
+	return matches
+}
+
+// Suggest returns standard library import paths that are similar to path,
+// ordered from most to least similar. It is used to provide "did you mean"
+// suggestions when a package could not be found.
+//
+// Paths starting with a domain name, such as github.com/user/repo, never
+// return suggestions.
+func Suggest(path string) []string {
+	const max = 5
+
+	path, _ = SplitVersion(path)
+	if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+		return nil
+	}
+
+	name := path[strings.LastIndex(path, "/")+1:]
+	pkgs, _ := stdlibPackages()
+
+	type suggestion struct {
+		pkg  string
+		dist int
+	}
+	var suggestions []suggestion
+	for _, pkg := range pkgs {
+		if pkg == path {
+			continue
+		}
+		last := pkg[strings.LastIndex(pkg, "/")+1:]
+		dist := levenshtein(path, pkg)
+		if d := levenshtein(name, last); d < dist {
+			dist = d
+		}
+		if dist <= len(name)/4+1 {
+			suggestions = append(suggestions, suggestion{pkg, dist})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].dist < suggestions[j].dist
+	})
+	if len(suggestions) > max {
+		suggestions = suggestions[:max]
+	}
+
+	result := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		result = append(result, s.pkg)
+	}
+	return result
+}
+
+// SuggestionError is returned by searchers when a package could not be found,
+// and there are standard library packages with a similar import path.
+type SuggestionError struct {
+	Module      string
+	Suggestions []string
+	Err         error
+}
+
+// Error satisfies the error interface.
+func (err *SuggestionError) Error() string {
+	return fmt.Sprintf("%v, did you mean %s?", err.Err, strings.Join(err.Suggestions, ", "))
+}
+
+// Unwrap returns the original error, so that errors.Is(err, ErrNotFound) still
+// reports true.
+func (err *SuggestionError) Unwrap() error {
+	return err.Err
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,35 @@
+package doc_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hhhapz/doc"
+)
+
+func TestExpandShortName(t *testing.T) {
+	tests := map[string][]string{
+		"http":      {"net/http"},
+		"bytes":     {"bytes"},
+		"template":  {"html/template", "text/template"},
+		"x509/pkix": {"crypto/x509/pkix"},
+		"notapkg":   nil,
+	}
+	for name, want := range tests {
+		if got := doc.ExpandShortName(name); !slices.Equal(got, want) {
+			t.Errorf("ExpandShortName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	if got := doc.Suggest("byts"); !slices.Contains(got, "bytes") {
+		t.Errorf("Suggest(byts) = %q, expected bytes", got)
+	}
+	if got := doc.Suggest("net/htp"); len(got) == 0 || got[0] != "net/http" {
+		t.Errorf("Suggest(net/htp) = %q, expected net/http first", got)
+	}
+	if got := doc.Suggest("github.com/foo/http"); got != nil {
+		t.Errorf("Suggest(github.com/foo/http) = %q, expected none", got)
+	}
+}
@@ -0,0 +1,87 @@
+package doc
+
+import "time"
+
+// Store is the storage backend of a cached searcher. The default store keeps
+// packages in an in-memory map, NewFileStore keeps them on disk.
+//
+// The cached searcher serializes all writes to the store, but Get may be
+// called concurrently with other calls to Get.
+type Store interface {
+	// Get returns the package stored under key, and false if there is none.
+	Get(key string) (*CachedPackage, bool, error)
+	// Put stores the package under key, replacing any existing package.
+	Put(key string, pkg *CachedPackage) error
+	// Delete removes the package stored under key, if there is one.
+	Delete(key string) error
+	// Touch sets the Updated time of the package stored under key.
+	Touch(key string, updated time.Time) error
+	// Entries lists the keys and timestamps of all stored packages.
+	Entries() ([]StoreEntry, error)
+	// Size returns the approximate number of bytes used by the stored
+	// packages.
+	Size() (int64, error)
+}
+
+// StoreEntry describes a package in a Store, without its contents.
+type StoreEntry struct {
+	Key     string
+	Created time.Time
+	Updated time.Time
+}
+
+// memoryStore is a Store that keeps packages in a map.
+type memoryStore map[string]*CachedPackage
+
+// memoryStore implements the Store interface.
+var _ Store = memoryStore{}
+
+// NewMemoryStore creates a Store that keeps packages in memory. It is the
+// default store of NewCachedSearcher.
+func NewMemoryStore() Store {
+	return memoryStore{}
+}
+
+func (m memoryStore) Get(key string) (*CachedPackage, bool, error) {
+	cPkg, ok := m[key]
+	return cPkg, ok, nil
+}
+
+func (m memoryStore) Put(key string, pkg *CachedPackage) error {
+	m[key] = pkg
+	return nil
+}
+
+func (m memoryStore) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m memoryStore) Touch(key string, updated time.Time) error {
+	if cPkg, ok := m[key]; ok {
+		cPkg.Updated = updated
+	}
+	return nil
+}
+
+func (m memoryStore) Entries() ([]StoreEntry, error) {
+	entries := make([]StoreEntry, 0, len(m))
+	for key, cPkg := range m {
+		entries = append(entries, StoreEntry{
+			Key:     key,
+			Created: cPkg.Created,
+			Updated: cPkg.Updated,
+		})
+	}
+	return entries, nil
+}
+
+// Size returns the approximate memory used by the packages. Like Entries, a
+// package stored under more than one key is counted once for every key.
+func (m memoryStore) Size() (int64, error) {
+	var n int64
+	for key, cPkg := range m {
+		n += int64(len(key)) + cPkg.Package.size()
+	}
+	return n, nil
+}
@@ -0,0 +1,41 @@
+package doc
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrVersionUnsupported is returned by searchers when a specific version of a
+// module is requested, but the site being searched can only provide the latest
+// version.
+var ErrVersionUnsupported = errors.New("version lookups are not supported by this parser")
+
+// VersionedParser is implemented by parsers whose site can serve the
+// documentation for a specific version of a module.
+type VersionedParser interface {
+	Parser
+	// VersionURL returns the url to the documentation of path at version. The
+	// version may be a semantic version, a pseudo version, or a branch name such
+	// as master.
+	VersionURL(path, version string) (full string)
+}
+
+// SplitVersion splits a module query of the form path@version into the path and
+// the version. If no version is present, or the version is "latest", version
+// will be empty.
+func SplitVersion(module string) (path, version string) {
+	path, version, _ = strings.Cut(module, "@")
+	if version == "latest" {
+		version = ""
+	}
+	return path, version
+}
+
+// JoinVersion is the inverse of SplitVersion. If version is empty, only the
+// path is returned.
+func JoinVersion(path, version string) string {
+	if version == "" || version == "latest" {
+		return path
+	}
+	return path + "@" + version
+}
@@ -0,0 +1,144 @@
+package doc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestSplitVersion(t *testing.T) {
+	tests := []struct {
+		module, path, version string
+	}{
+		{"example.com/pkg", "example.com/pkg", ""},
+		{"example.com/pkg@v1.2.3", "example.com/pkg", "v1.2.3"},
+		{"example.com/pkg@latest", "example.com/pkg", ""},
+		{"example.com/pkg@master", "example.com/pkg", "master"},
+		{"example.com/pkg@", "example.com/pkg", ""},
+	}
+	for _, tt := range tests {
+		path, version := SplitVersion(tt.module)
+		if path != tt.path || version != tt.version {
+			t.Errorf("SplitVersion(%q) = %q, %q, want %q, %q", tt.module, path, version, tt.path, tt.version)
+		}
+	}
+}
+
+func TestJoinVersion(t *testing.T) {
+	tests := []struct {
+		path, version, module string
+	}{
+		{"example.com/pkg", "", "example.com/pkg"},
+		{"example.com/pkg", "latest", "example.com/pkg"},
+		{"example.com/pkg", "v1.2.3", "example.com/pkg@v1.2.3"},
+	}
+	for _, tt := range tests {
+		if module := JoinVersion(tt.path, tt.version); module != tt.module {
+			t.Errorf("JoinVersion(%q, %q) = %q, want %q", tt.path, tt.version, module, tt.module)
+		}
+	}
+}
+
+// versionedParser serves versions at /path/@v/version, and parses the version
+// of the page from its <h2>.
+type versionedParser struct {
+	stubParser
+}
+
+func (p versionedParser) VersionURL(path, version string) string {
+	return p.base + "/" + path + "/@v/" + version
+}
+
+func (p versionedParser) Parse(document *goquery.Document, _, _ bool) (Package, error) {
+	return Package{
+		Name:    document.Find("title").Text(),
+		Version: document.Find("h2").Text(),
+	}, nil
+}
+
+// versionServer serves v1.0.0 as the latest version of every module, and any
+// other requested version as is.
+func versionServer(requests *atomic.Int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		path, version, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/@v/")
+		if version == "" {
+			version = "v1.0.0"
+		}
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body><h2>%s</h2></body></html>", path, version)
+	}))
+}
+
+func TestSearchVersion(t *testing.T) {
+	var requests atomic.Int64
+	srv := versionServer(&requests)
+	defer srv.Close()
+	s := NewSearcher(versionedParser{stubParser{srv.URL}}, WithClient(srv.Client()))
+
+	for module, want := range map[string]string{
+		"example.com/pkg":        "v1.0.0",
+		"example.com/pkg@latest": "v1.0.0",
+		"example.com/pkg@v0.9.0": "v0.9.0",
+	} {
+		pkg, err := s.Search(context.Background(), module)
+		if err != nil {
+			t.Fatalf("could not search %q: %v", module, err)
+		}
+		if pkg.Name != "example.com/pkg" || pkg.Version != want {
+			t.Errorf("expected example.com/pkg at %s for %q, got %q at %q", want, module, pkg.Name, pkg.Version)
+		}
+	}
+}
+
+func TestSearchVersionUnsupported(t *testing.T) {
+	var requests atomic.Int64
+	srv := versionServer(&requests)
+	defer srv.Close()
+	s := NewSearcher(stubParser{srv.URL}, WithClient(srv.Client()))
+
+	if _, err := s.Search(context.Background(), "example.com/pkg@v1.0.0"); !errors.Is(err, ErrVersionUnsupported) {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+	if _, err := s.Search(context.Background(), "example.com/pkg@latest"); err != nil {
+		t.Errorf("expected latest version to be supported, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestCacheResolvedVersion(t *testing.T) {
+	var requests atomic.Int64
+	srv := versionServer(&requests)
+	defer srv.Close()
+	c := NewCachedSearcher(versionedParser{stubParser{srv.URL}}, WithClient(srv.Client()))
+	defer c.Close()
+
+	ctx := context.Background()
+	for _, module := range []string{"example.com/pkg", "example.com/pkg@latest", "example.com/pkg@v1.0.0"} {
+		pkg, err := c.Search(ctx, module)
+		if err != nil {
+			t.Fatalf("could not search %q: %v", module, err)
+		}
+		if pkg.Version != "v1.0.0" {
+			t.Errorf("expected v1.0.0 for %q, got %q", module, pkg.Version)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected the resolved version to be cached, got %d requests", n)
+	}
+
+	if _, err := c.Search(ctx, "example.com/pkg@v0.9.0"); err != nil {
+		t.Fatalf("could not search older version: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected a request for an uncached version, got %d requests", n)
+	}
+}